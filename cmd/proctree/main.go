@@ -0,0 +1,97 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+// Command proctree
+// render process tree of config file as Graphviz DOT or Mermaid
+// diagram.
+//
+//   proctree -c tree.json -f mermaid > tree.mmd
+//   proctree -c tree.json | dot -Tsvg > tree.svg
+//
+// Config file is a json document of process tree.
+//
+//   {
+//       "name": "root",
+//       "propagate": "parent",
+//       "children": [
+//           {"name": "consumer", "unbind_when_stopped": true, "tags": ["io"]},
+//           {"name": "scheduler"}
+//       ]
+//   }
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fuyibing/util/v8/process"
+)
+
+type (
+	node struct {
+		Name              string              `json:"name"`
+		Propagate         process.Propagation `json:"propagate"`
+		UnbindWhenStopped bool                `json:"unbind_when_stopped"`
+		Tags              []string            `json:"tags"`
+		Children          []*node             `json:"children"`
+	}
+)
+
+func main() {
+	var (
+		config = flag.String("c", "", "config file of process tree, read stdin if - given")
+		format = flag.String("f", "dot", "output format, dot or mermaid")
+	)
+	flag.Parse()
+
+	if err := run(*config, *format); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "proctree: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(config, format string) (err error) {
+	var buf []byte
+
+	switch config {
+	case "":
+		return fmt.Errorf("config file not specified")
+	case "-":
+		buf, err = ioutil.ReadAll(os.Stdin)
+	default:
+		buf, err = ioutil.ReadFile(config)
+	}
+	if err != nil {
+		return
+	}
+
+	root := &node{}
+	if err = json.Unmarshal(buf, root); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+
+	snapshot := root.build().Snapshot()
+	switch format {
+	case "dot":
+		fmt.Print(snapshot.Dot())
+	case "mermaid":
+		fmt.Print(snapshot.Mermaid())
+	default:
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+	return
+}
+
+func (o *node) build() process.Processor {
+	p := process.New(o.Name).UnbindWhenStopped(o.UnbindWhenStopped).Tag(o.Tags...)
+	if o.Propagate != "" {
+		p.Propagate(o.Propagate)
+	}
+	for _, c := range o.Children {
+		p.Add(c.build())
+	}
+	return p
+}
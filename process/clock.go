@@ -0,0 +1,41 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"time"
+)
+
+var (
+	// DefaultClock
+	// used by process if no clock configured.
+	DefaultClock Clock = realClock{}
+)
+
+type (
+	// Clock
+	// time source of process.
+	//
+	// Tests inject a fake clock to drive time based behaviors
+	// such as leader retries without sleeping.
+	Clock interface {
+		// After
+		// return channel which receive current time after
+		// duration elapsed.
+		After(d time.Duration) <-chan time.Time
+
+		// Now
+		// return current time.
+		Now() time.Time
+	}
+
+	// Observer
+	// auto called when lifetime state of process changed.
+	Observer func(p Processor, from, to State)
+
+	realClock struct{}
+)
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Now() time.Time                         { return time.Now() }
@@ -0,0 +1,185 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LabelPhase
+	// pprof label key of process phase.
+	LabelPhase = "phase"
+
+	// LabelProcess
+	// pprof label key of process path.
+	LabelProcess = "process"
+
+	PhaseAfter    = "after"
+	PhaseBefore   = "before"
+	PhaseCallback = "callback"
+	PhaseStart    = "start"
+)
+
+type (
+	// Stack
+	// goroutines with the same stack and labels.
+	Stack struct {
+		Count  int
+		Labels map[string]string
+		Frames []string
+	}
+)
+
+// Dump
+// write state tree of process with goroutine stacks belonging
+// to each process into w.
+//
+// Goroutines are matched by pprof labels set by process when
+// launching events and subprocesses, goroutines started by
+// events inherit the labels.
+//
+//   root [running] restarts=0
+//       1 goroutine(s) phase=callback
+//           main.consume+0x24    /app/main.go:12
+//       consumer [running] restarts=2
+//           ...
+func Dump(w io.Writer, p Processor) error {
+	stacks, err := Stacks()
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string][]*Stack)
+	for _, s := range stacks {
+		path := s.Labels[LabelProcess]
+		groups[path] = append(groups[path], s)
+	}
+
+	bw := bufio.NewWriter(w)
+	dumpSnapshot(bw, p.Snapshot(), groups, 0)
+	return bw.Flush()
+}
+
+// DumpHandler
+// return http handler which write dump of process, it is
+// designed for admin servers.
+//
+//   mux.Handle("/debug/process", process.DumpHandler(root))
+func DumpHandler(p Processor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := Dump(w, p); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// DumpOnSignal
+// write dump of process into w when signals received, default
+// signals are SIGQUIT and SIGUSR1 on unix. Signals must be given
+// on other systems, nothing is listened if no signals. Return
+// function to stop listening.
+//
+//   stop := process.DumpOnSignal(root, os.Stderr)
+//   defer stop()
+func DumpOnSignal(p Processor, w io.Writer, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = defaultDumpSignals
+	}
+	if len(signals) == 0 {
+		return func() {}
+	}
+
+	var (
+		ch   = make(chan os.Signal, 1)
+		quit = make(chan struct{})
+	)
+
+	signal.Notify(ch, signals...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = Dump(w, p)
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(quit)
+	}
+}
+
+// Stacks
+// return goroutine stacks of current process with labels.
+func Stacks() ([]*Stack, error) {
+	buf := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(buf, 1); err != nil {
+		return nil, err
+	}
+	return parseStacks(buf.String()), nil
+}
+
+func dumpSnapshot(w io.Writer, s *Snapshot, groups map[string][]*Stack, depth int) {
+	indent := strings.Repeat("    ", depth)
+	_, _ = fmt.Fprintf(w, "%s%s [%s] restarts=%d", indent, s.Name, s.State, s.Restarts)
+	if len(s.Tags) > 0 {
+		_, _ = fmt.Fprintf(w, " tags=%s", strings.Join(s.Tags, ","))
+	}
+	_, _ = fmt.Fprintln(w)
+
+	for _, g := range groups[s.Path] {
+		_, _ = fmt.Fprintf(w, "%s    %d goroutine(s) %s=%s\n", indent, g.Count, LabelPhase, g.Labels[LabelPhase])
+		for _, frame := range g.Frames {
+			_, _ = fmt.Fprintf(w, "%s        %s\n", indent, frame)
+		}
+	}
+
+	for _, c := range s.Children {
+		dumpSnapshot(w, c, groups, depth+1)
+	}
+}
+
+// Parse
+// goroutine profile in debug=1 format.
+//
+//   2 @ 0x43a1b6 0x4068cc
+//   # labels: {"phase":"callback", "process":"root"}
+//   #	0x4b6c04	main.block+0x24	/app/main.go:12
+func parseStacks(text string) (stacks []*Stack) {
+	var s *Stack
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.Contains(line, " @ 0x"):
+			n, _ := strconv.Atoi(strings.SplitN(line, " ", 2)[0])
+			s = &Stack{Count: n, Labels: make(map[string]string)}
+			stacks = append(stacks, s)
+		case s == nil:
+			continue
+		case strings.HasPrefix(line, "# labels: "):
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "# labels: ")), &s.Labels)
+		case strings.HasPrefix(line, "#\t"):
+			if fields := strings.Fields(strings.TrimPrefix(line, "#\t")); len(fields) >= 3 {
+				s.Frames = append(s.Frames, fmt.Sprintf("%s    %s", fields[1], fields[2]))
+			}
+		case line == "":
+			s = nil
+		}
+	}
+	return
+}
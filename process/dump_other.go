@@ -0,0 +1,17 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package process
+
+import (
+	"os"
+)
+
+var (
+	// No default signals, os.Interrupt is not used as it stops
+	// process.
+	defaultDumpSignals []os.Signal
+)
@@ -0,0 +1,65 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
+)
+
+func TestDump(t *testing.T) {
+	block := func(ctx context.Context) (ignored bool) {
+		<-ctx.Done()
+		return
+	}
+
+	// Goroutine started by event inherits labels.
+	worker := func(ctx context.Context) (ignored bool) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			<-ctx.Done()
+		}()
+		<-done
+		return
+	}
+
+	root := process.New("root").Callback(block).Add(
+		process.New("c1").Callback(worker).Tag("io"),
+	)
+
+	h := processtest.New(t, root)
+	h.Start()
+	h.ExpectState("root/c1", process.StateRunning)
+
+	buf := &bytes.Buffer{}
+	if err := process.Dump(buf, root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stacks are listed under process in order.
+	text, offset := buf.String(), 0
+	for _, s := range []string{
+		"root [running] restarts=0\n",
+		"goroutine(s) phase=callback\n",
+		"    c1 [running] restarts=0 tags=io\n",
+		"goroutine(s) phase=callback\n",
+		"process_test.TestDump.func2.1",
+	} {
+		i := strings.Index(text[offset:], s)
+		if i < 0 {
+			t.Fatalf("dump missing %q:\n%s", s, text)
+		}
+		offset += i + len(s)
+	}
+
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
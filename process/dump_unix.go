@@ -0,0 +1,16 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	defaultDumpSignals = []os.Signal{syscall.SIGQUIT, syscall.SIGUSR1}
+)
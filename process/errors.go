@@ -0,0 +1,52 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// ChildError
+	// error returned by failed subprocess.
+	ChildError struct {
+		Name string
+		Err  error
+	}
+
+	// GroupError
+	// error returned by process Start if any subprocess failed
+	// and propagation configured.
+	GroupError struct {
+		// Name
+		// of the process.
+		Name string
+
+		// Err
+		// returned by the process itself, nil if process
+		// stopped normally.
+		Err error
+
+		// Children
+		// failed subprocesses in failure order.
+		Children []*ChildError
+	}
+)
+
+func (o *ChildError) Error() string { return fmt.Sprintf("subprocess '%s': %v", o.Name, o.Err) }
+func (o *ChildError) Unwrap() error { return o.Err }
+
+func (o *GroupError) Error() string {
+	list := make([]string, 0, len(o.Children)+1)
+	if o.Err != nil {
+		list = append(list, o.Err.Error())
+	}
+	for _, c := range o.Children {
+		list = append(list, c.Error())
+	}
+	return fmt.Sprintf("process '%s': %s", o.Name, strings.Join(list, "; "))
+}
+
+func (o *GroupError) Unwrap() error { return o.Err }
@@ -0,0 +1,100 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	graphColors = map[State]string{
+		StateStarting:   "lightyellow",
+		StateRunning:    "palegreen",
+		StateRestarting: "orange",
+		StateStopping:   "lightpink",
+		StateStopped:    "lightgrey",
+	}
+)
+
+// Dot
+// render snapshot tree as Graphviz DOT diagram, each edge runs
+// from parent to the subprocess which depends on it.
+//
+//   digraph "root" {
+//       node [shape=box, style="rounded,filled"];
+//       "root" [label="root\nstate: running\nrestarts: 0\npropagate: none", fillcolor="palegreen"];
+//       "root/c1" [label="c1\nstate: running\nrestarts: 1\npropagate: none", fillcolor="palegreen"];
+//       "root" -> "root/c1";
+//   }
+func (o *Snapshot) Dot() string {
+	buf := &strings.Builder{}
+	_, _ = fmt.Fprintf(buf, "digraph %q {\n", o.Path)
+	_, _ = fmt.Fprintf(buf, "    node [shape=box, style=\"rounded,filled\"];\n")
+
+	o.Walk(func(s *Snapshot) bool {
+		_, _ = fmt.Fprintf(buf, "    %q [label=%q, fillcolor=%q];\n", s.Path, strings.Join(s.labels(), "\n"), graphColors[s.State])
+		for _, c := range s.Children {
+			_, _ = fmt.Fprintf(buf, "    %q -> %q;\n", s.Path, c.Path)
+		}
+		return true
+	})
+
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid
+// render snapshot tree as Mermaid flowchart, each edge runs
+// from parent to the subprocess which depends on it.
+//
+//   graph TD
+//       n0["root<br/>state: running<br/>restarts: 0<br/>propagate: none"]
+//       n1["c1<br/>state: running<br/>restarts: 1<br/>propagate: none"]
+//       n0 --> n1
+//       class n0 running
+//       class n1 running
+func (o *Snapshot) Mermaid() string {
+	var (
+		buf = &strings.Builder{}
+		ids = make(map[*Snapshot]string)
+	)
+
+	buf.WriteString("graph TD\n")
+	o.Walk(func(s *Snapshot) bool {
+		ids[s] = fmt.Sprintf("n%d", len(ids))
+		_, _ = fmt.Fprintf(buf, "    %s[\"%s\"]\n", ids[s], strings.Replace(strings.Join(s.labels(), "<br/>"), `"`, "#quot;", -1))
+		return true
+	})
+	o.Walk(func(s *Snapshot) bool {
+		for _, c := range s.Children {
+			_, _ = fmt.Fprintf(buf, "    %s --> %s\n", ids[s], ids[c])
+		}
+		return true
+	})
+	for _, state := range []State{StateStarting, StateRunning, StateRestarting, StateStopping, StateStopped} {
+		_, _ = fmt.Fprintf(buf, "    classDef %s fill:%s\n", state, graphColors[state])
+	}
+	o.Walk(func(s *Snapshot) bool {
+		_, _ = fmt.Fprintf(buf, "    class %s %s\n", ids[s], s.State)
+		return true
+	})
+	return buf.String()
+}
+
+func (o *Snapshot) labels() []string {
+	list := []string{
+		o.Name,
+		fmt.Sprintf("state: %s", o.State),
+		fmt.Sprintf("restarts: %d", o.Restarts),
+		fmt.Sprintf("propagate: %s", o.Propagate),
+	}
+	if o.UnbindWhenStopped {
+		list = append(list, "unbind when stopped")
+	}
+	if len(o.Tags) > 0 {
+		list = append(list, fmt.Sprintf("tags: %s", strings.Join(o.Tags, ", ")))
+	}
+	return list
+}
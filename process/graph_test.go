@@ -0,0 +1,42 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+)
+
+func TestSnapshot_Dot(t *testing.T) {
+	s := process.New("root").Propagate(process.PropagateParent).Add(process.New("c1"), process.New("c2").UnbindWhenStopped(true)).Snapshot()
+
+	if c, ok := s.Find("root/c2"); !ok || !c.UnbindWhenStopped || c.State != process.StateStopped {
+		t.Fatalf("unexpected snapshot of root/c2: %+v", c)
+	}
+
+	dot := s.Dot()
+	for _, line := range []string{
+		`"root" [label="root\nstate: stopped\nrestarts: 0\npropagate: parent", fillcolor="lightgrey"];`,
+		`"root" -> "root/c1";`,
+		`"root" -> "root/c2";`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Fatalf("dot missing %s:\n%s", line, dot)
+		}
+	}
+
+	mermaid := s.Mermaid()
+	for _, line := range []string{
+		`n0["root<br/>state: stopped<br/>restarts: 0<br/>propagate: parent"]`,
+		`n0 --> n1`,
+		`n0 --> n2`,
+		`class n2 stopped`,
+	} {
+		if !strings.Contains(mermaid, line) {
+			t.Fatalf("mermaid missing %s:\n%s", line, mermaid)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"fmt"
+	"sync"
+)
+
+type (
+	// Handle
+	// of process started by StartAsync.
+	Handle struct {
+		done, started   chan struct{}
+		err, startedErr error
+		once            sync.Once
+	}
+)
+
+func newHandle() *Handle {
+	return &Handle{done: make(chan struct{}), started: make(chan struct{})}
+}
+
+// Done
+// return channel closed when Start returned.
+func (o *Handle) Done() <-chan struct{} { return o.done }
+
+// Err
+// return error of process.
+//
+// Return startup error if startup failed, or error of Start if
+// done, otherwise return nil.
+func (o *Handle) Err() error {
+	select {
+	case <-o.started:
+		if o.startedErr != nil {
+			return o.startedErr
+		}
+	default:
+		return nil
+	}
+
+	select {
+	case <-o.done:
+		return o.err
+	default:
+		return nil
+	}
+}
+
+// Started
+// return channel closed when before events succeed and main
+// events begin, or startup failed.
+//
+// Startup failed if process started already, any before event
+// panic or ignored, or process stopped before main events.
+func (o *Handle) Started() <-chan struct{} { return o.started }
+
+func (o *Handle) finish(name string, err error) {
+	if o == nil {
+		return
+	}
+
+	if err != nil {
+		o.resolve(err)
+	} else {
+		o.resolve(fmt.Errorf("process '%s' not started", name))
+	}
+
+	o.err = err
+	close(o.done)
+}
+
+func (o *Handle) resolve(err error) {
+	if o == nil {
+		return
+	}
+
+	o.once.Do(func() {
+		o.startedErr = err
+		close(o.started)
+	})
+}
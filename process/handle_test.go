@@ -0,0 +1,56 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+)
+
+func TestProcessor_StartAsync(t *testing.T) {
+	block := func(ctx context.Context) (ignored bool) {
+		<-ctx.Done()
+		return
+	}
+
+	// Started.
+	p := process.New("p1").Callback(block)
+	h := p.StartAsync(context.Background())
+	<-h.Started()
+	if err := h.Err(); err != nil {
+		t.Fatalf("expect started, got %v", err)
+	}
+	if p.State() != process.StateRunning {
+		t.Fatalf("expect running, got %s", p.State())
+	}
+
+	// Started already.
+	h2 := p.StartAsync(context.Background())
+	<-h2.Done()
+	if err := h2.Err(); err == nil {
+		t.Fatalf("expect started already error")
+	}
+
+	p.Stop()
+	<-h.Done()
+	if err := h.Err(); err != nil {
+		t.Fatalf("expect stopped, got %v", err)
+	}
+
+	// Before event panic.
+	h = process.New("p2").Before(func(ctx context.Context) (ignored bool) { panic("boom") }).Callback(block).StartAsync(context.Background())
+	<-h.Started()
+	if err := h.Err(); err == nil || err.Error() != "boom" {
+		t.Fatalf("expect before error, got %v", err)
+	}
+
+	// Before event ignored.
+	h = process.New("p3").Before(func(ctx context.Context) (ignored bool) { return true }).Callback(block).StartAsync(context.Background())
+	<-h.Started()
+	if err := h.Err(); err == nil || err.Error() != "process 'p3' not started" {
+		t.Fatalf("expect not started error, got %v", err)
+	}
+}
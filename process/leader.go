@@ -0,0 +1,142 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultLeaderInterval = time.Second
+)
+
+type (
+	// LeaderOption
+	// configure leader created by NewLeader.
+	LeaderOption func(o *leader)
+
+	leader struct {
+		child     Processor
+		interval  time.Duration
+		locker    Locker
+		onError   func(p Processor, err error)
+		processor *processor
+	}
+)
+
+// NewLeader
+// create and return processor which run child only while it
+// holds the lease of locker.
+//
+// Leader retry to acquire the lease every interval, start child
+// once acquired, then refresh the lease every interval. Child
+// will be stopped if lease lost, and leader keeps retrying to
+// take over again. Errors returned by locker are ignored unless
+// WithLeaderError given.
+//
+//   proc.Add(process.NewLeader("scheduler-leader",
+//       process.NewFileLocker("/var/run/scheduler.lock", time.Second*10),
+//       time.Second*3,
+//       process.New("scheduler").Callback(scheduler),
+//       process.WithLeaderError(func(p process.Processor, err error) {
+//           log.Printf("leader '%s': %v", process.Path(p), err)
+//       }),
+//   ))
+func NewLeader(name string, locker Locker, interval time.Duration, child Processor, opts ...LeaderOption) Processor {
+	if interval <= 0 {
+		interval = DefaultLeaderInterval
+	}
+
+	o := &leader{child: child, interval: interval, locker: locker}
+	for _, opt := range opts {
+		opt(o)
+	}
+	// Child is started by leader itself rather than process
+	// lifetime.
+	o.processor = (&processor{name: name, manual: true}).init()
+	o.processor.Callback(o.run).Add(child)
+	return o.processor
+}
+
+// WithLeaderError
+// set function called with error returned by locker, leader
+// keeps competing for the lease after error.
+func WithLeaderError(fn func(p Processor, err error)) LeaderOption {
+	return func(o *leader) { o.onError = fn }
+}
+
+// /////////////////////////////////////////////////////////////
+// Leader methods.
+// /////////////////////////////////////////////////////////////
+
+func (o *leader) run(ctx context.Context) (ignored bool) {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	// Stop child and give up lease when leader stopped.
+	defer func() {
+		if done != nil {
+			cancel()
+			<-done
+		}
+		o.report(o.locker.Release())
+	}()
+
+	for {
+		if done == nil {
+			// Start child if lease acquired.
+			acquired, err := o.locker.Acquire(ctx)
+			o.report(err)
+			if acquired {
+				cancel, done = o.start(ctx)
+			}
+		} else {
+			select {
+			case <-done:
+				// Child stopped by itself, give up lease and
+				// compete again.
+				cancel()
+				cancel, done = nil, nil
+				o.report(o.locker.Release())
+			default:
+				// Stop child if lease lost.
+				held, err := o.locker.Refresh(ctx)
+				o.report(err)
+				if !held {
+					cancel()
+					<-done
+					cancel, done = nil, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.processor.getClock().After(o.interval):
+		}
+	}
+}
+
+func (o *leader) report(err error) {
+	if err != nil && o.onError != nil {
+		o.onError(o.processor, err)
+	}
+}
+
+func (o *leader) start(ctx context.Context) (cancel context.CancelFunc, done chan struct{}) {
+	var c context.Context
+	c, cancel = context.WithCancel(ctx)
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		doChildLabels(c, o.child)
+		_ = o.child.Start(c)
+	}()
+	return
+}
@@ -0,0 +1,239 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
+)
+
+func TestFileLocker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(dir, "leader.lock")
+		l1   = process.NewFileLocker(path, time.Millisecond*50)
+		l2   = process.NewFileLocker(path, time.Millisecond*50)
+	)
+
+	if ok, err := l1.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("l1 acquire: %v, %v", ok, err)
+	}
+	if ok, err := l2.Acquire(ctx); err != nil || ok {
+		t.Fatalf("l2 acquire while held: %v, %v", ok, err)
+	}
+
+	// Lease expired, l2 take over and l1 lost.
+	time.Sleep(time.Millisecond * 80)
+	if ok, err := l2.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("l2 acquire expired: %v, %v", ok, err)
+	}
+	if ok, err := l1.Refresh(ctx); err != nil || ok {
+		t.Fatalf("l1 refresh after lost: %v, %v", ok, err)
+	}
+
+	if err = l2.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := l1.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("l1 acquire released: %v, %v", ok, err)
+	}
+}
+
+func TestFileLocker_Concurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "leader.lock")
+	for round := 0; round < 50; round++ {
+		// Expired lease of crashed replica.
+		if err = ioutil.WriteFile(path, []byte("crashed"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		expired := time.Now().Add(-time.Minute)
+		if err = os.Chtimes(path, expired, expired); err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			acquired int32
+			start    = make(chan struct{})
+			wg       sync.WaitGroup
+		)
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func(l process.Locker) {
+				defer wg.Done()
+				<-start
+				if ok, err := l.Acquire(context.Background()); err != nil {
+					t.Error(err)
+				} else if ok {
+					atomic.AddInt32(&acquired, 1)
+				}
+			}(process.NewFileLocker(path, time.Second))
+		}
+		close(start)
+		wg.Wait()
+
+		if acquired != 1 {
+			t.Fatalf("round %d: expected exactly one locker take over, got %d", round, acquired)
+		}
+	}
+}
+
+func TestFileLocker_Release(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(dir, "leader.lock")
+		l1   = process.NewFileLocker(path, time.Millisecond*50)
+		l2   = process.NewFileLocker(path, time.Millisecond*50)
+	)
+
+	if ok, err := l1.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("l1 acquire: %v, %v", ok, err)
+	}
+	time.Sleep(time.Millisecond * 80)
+	if ok, err := l2.Acquire(ctx); err != nil || !ok {
+		t.Fatalf("l2 acquire expired: %v, %v", ok, err)
+	}
+
+	// Release of lost lease keeps lease of new holder.
+	if err = l1.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := l2.Refresh(ctx); err != nil || !ok {
+		t.Fatalf("l2 refresh after l1 released: %v, %v", ok, err)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	var (
+		ctx = context.Background()
+		l1  = process.NewMemoryLocker("memory-locker", time.Minute)
+		l2  = process.NewMemoryLocker("memory-locker", time.Minute)
+	)
+
+	if ok, _ := l1.Acquire(ctx); !ok {
+		t.Fatal("l1 should acquire")
+	}
+	if ok, _ := l2.Acquire(ctx); ok {
+		t.Fatal("l2 should not acquire while l1 held")
+	}
+	if ok, _ := l2.Refresh(ctx); ok {
+		t.Fatal("l2 should not refresh lease of l1")
+	}
+
+	_ = l1.Release()
+	if ok, _ := l2.Acquire(ctx); !ok {
+		t.Fatal("l2 should acquire after l1 released")
+	}
+	_ = l2.Release()
+}
+
+func TestNewLeader(t *testing.T) {
+	block := func(ctx context.Context) (ignored bool) {
+		<-ctx.Done()
+		return
+	}
+
+	root := process.New("root").Callback(block).Add(
+		process.NewLeader("l1", process.NewMemoryLocker("leader", time.Minute), time.Second, process.New("c1").Callback(block)),
+		process.NewLeader("l2", process.NewMemoryLocker("leader", time.Minute), time.Second, process.New("c2").Callback(block)),
+	)
+
+	h := processtest.New(t, root)
+	h.Start()
+
+	// Only one child is running while both leaders waiting for
+	// next retry.
+	children := map[string]string{"root/l1": "root/l1/c1", "root/l2": "root/l2/c2"}
+	running := func(s *process.Snapshot, path string) bool {
+		c, _ := s.Find(path)
+		return c.State == process.StateRunning
+	}
+
+	h.Clock.BlockUntil(2)
+	h.Expect("any child running", func(s *process.Snapshot) bool {
+		return running(s, "root/l1/c1") || running(s, "root/l2/c2")
+	})
+
+	leader, follower := "root/l1", "root/l2"
+	if running(root.Snapshot(), "root/l2/c2") {
+		leader, follower = follower, leader
+	}
+	if s, _ := root.Snapshot().Find(children[follower]); s.State != process.StateStopped {
+		t.Fatalf("follower child should not run, got %s", s.State)
+	}
+
+	// Stop leader, follower take over on next retry.
+	h.StopChild(leader)
+	h.Clock.Advance(time.Second)
+	h.ExpectState(children[follower], process.StateRunning)
+
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewLeader_Error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "locker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	errs := make(chan error, 10)
+	report := process.WithLeaderError(func(p process.Processor, err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	// Directory of lock path not exists.
+	locker := process.NewFileLocker(filepath.Join(dir, "missing", "leader.lock"), time.Minute)
+	root := process.New("root").Callback(func(ctx context.Context) (ignored bool) {
+		<-ctx.Done()
+		return
+	}).Add(process.NewLeader("l1", locker, time.Second, process.New("c1"), report))
+
+	h := processtest.New(t, root)
+	h.Start()
+
+	select {
+	case err = <-errs:
+		if !os.IsNotExist(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected locker error reported")
+	}
+
+	if err = h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
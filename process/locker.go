@@ -0,0 +1,229 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	lockerSequence uint64
+	memoryLeases   = &memoryLeaseTable{leases: make(map[string]*memoryLease)}
+)
+
+type (
+	// Locker
+	// lease based lock used for leader election.
+	//
+	// Acquire and Refresh must be safe to call repeatedly, and
+	// the lease held by the locker expires if Refresh is not
+	// called within its ttl.
+	Locker interface {
+		// Acquire
+		// try to take the lease once, return true if lease is
+		// held by this locker.
+		Acquire(ctx context.Context) (acquired bool, err error)
+
+		// Refresh
+		// extend the lease, return false if lease was lost.
+		Refresh(ctx context.Context) (held bool, err error)
+
+		// Release
+		// give up the lease if held by this locker.
+		Release() error
+	}
+
+	fileLocker struct {
+		owner, path string
+		ttl         time.Duration
+	}
+
+	memoryLease struct {
+		expire time.Time
+		owner  string
+	}
+
+	memoryLeaseTable struct {
+		mu     sync.Mutex
+		leases map[string]*memoryLease
+	}
+
+	memoryLocker struct {
+		key, owner string
+		ttl        time.Duration
+	}
+)
+
+// NewFileLocker
+// create and return a locker based on lease file.
+//
+// Lockers of any process on the same host compete for the file
+// at path, the holder must refresh the lease within ttl, or it
+// will be taken over by another locker. Lease file is only read
+// and written while holding lock of guard file at path.guard,
+// the guard is process local on systems without file lock.
+//
+//   locker := process.NewFileLocker("/var/run/scheduler.lock", time.Second*10)
+func NewFileLocker(path string, ttl time.Duration) Locker {
+	return &fileLocker{owner: newLockerOwner(), path: path, ttl: ttl}
+}
+
+// NewMemoryLocker
+// create and return a locker based on memory.
+//
+// Lockers created with the same key compete for the lease in
+// current process.
+//
+//   locker := process.NewMemoryLocker("scheduler", time.Second*10)
+func NewMemoryLocker(key string, ttl time.Duration) Locker {
+	return &memoryLocker{key: key, owner: newLockerOwner(), ttl: ttl}
+}
+
+// /////////////////////////////////////////////////////////////
+// File locker.
+// /////////////////////////////////////////////////////////////
+
+func (o *fileLocker) Acquire(_ context.Context) (acquired bool, err error) {
+	err = o.guard(func() error {
+		owner, info, re := o.read()
+		if re != nil && !os.IsNotExist(re) {
+			return re
+		}
+
+		// Lease held by another locker and not expired.
+		if re == nil && owner != o.owner && time.Since(info.ModTime()) <= o.ttl {
+			return nil
+		}
+
+		// Create, refresh or take over expired lease.
+		if re = ioutil.WriteFile(o.path, []byte(o.owner), 0644); re != nil {
+			return re
+		}
+		acquired = true
+		return nil
+	})
+	return
+}
+
+func (o *fileLocker) Refresh(_ context.Context) (held bool, err error) {
+	err = o.guard(func() error {
+		owner, _, re := o.read()
+		if re != nil {
+			if os.IsNotExist(re) {
+				return nil
+			}
+			return re
+		}
+		if owner != o.owner {
+			return nil
+		}
+
+		now := time.Now()
+		if re = os.Chtimes(o.path, now, now); re != nil {
+			return re
+		}
+		held = true
+		return nil
+	})
+	return
+}
+
+func (o *fileLocker) Release() error {
+	return o.guard(func() error {
+		owner, _, err := o.read()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if owner != o.owner {
+			return nil
+		}
+		if err = os.Remove(o.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Guard
+// call fn while holding exclusive lock of guard file, so lease
+// file is read and written by one locker at the same time. Lock
+// is released by system if process exits.
+func (o *fileLocker) guard(fn func() error) error {
+	f, err := os.OpenFile(o.path+".guard", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	if err = lockFile(f); err != nil {
+		return err
+	}
+	defer func() { _ = unlockFile(f) }()
+	return fn()
+}
+
+func (o *fileLocker) read() (owner string, info os.FileInfo, err error) {
+	var buf []byte
+	if buf, err = ioutil.ReadFile(o.path); err != nil {
+		return
+	}
+	if info, err = os.Stat(o.path); err != nil {
+		return
+	}
+	owner = string(buf)
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Memory locker.
+// /////////////////////////////////////////////////////////////
+
+func (o *memoryLocker) Acquire(_ context.Context) (acquired bool, err error) {
+	memoryLeases.mu.Lock()
+	defer memoryLeases.mu.Unlock()
+
+	now := time.Now()
+	if l, ok := memoryLeases.leases[o.key]; ok && l.owner != o.owner && now.Before(l.expire) {
+		return false, nil
+	}
+
+	memoryLeases.leases[o.key] = &memoryLease{expire: now.Add(o.ttl), owner: o.owner}
+	return true, nil
+}
+
+func (o *memoryLocker) Refresh(_ context.Context) (held bool, err error) {
+	memoryLeases.mu.Lock()
+	defer memoryLeases.mu.Unlock()
+
+	now := time.Now()
+	if l, ok := memoryLeases.leases[o.key]; ok && l.owner == o.owner && now.Before(l.expire) {
+		l.expire = now.Add(o.ttl)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (o *memoryLocker) Release() error {
+	memoryLeases.mu.Lock()
+	defer memoryLeases.mu.Unlock()
+
+	if l, ok := memoryLeases.leases[o.key]; ok && l.owner == o.owner {
+		delete(memoryLeases.leases, o.key)
+	}
+	return nil
+}
+
+func newLockerOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), atomic.AddUint64(&lockerSequence, 1))
+}
@@ -0,0 +1,19 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package process
+
+import (
+	"os"
+	"sync"
+)
+
+// Guard of file lockers in current process, used on systems
+// without file lock.
+var fileGuard sync.Mutex
+
+func lockFile(*os.File) error   { fileGuard.Lock(); return nil }
+func unlockFile(*os.File) error { fileGuard.Unlock(); return nil }
@@ -8,6 +8,7 @@ package process
 import (
 	"context"
 	"fmt"
+	"runtime/pprof"
 	"sync"
 	"time"
 )
@@ -24,6 +25,14 @@ type (
 	// auto called if panic occurred in event.
 	PanicEvent func(ctx context.Context, v interface{})
 
+	// Propagation
+	// how process reacts when any subprocess failed.
+	Propagation string
+
+	// State
+	// lifetime state of process.
+	State string
+
 	// Processor
 	// run like os process.
 	Processor interface {
@@ -63,6 +72,11 @@ type (
 		// signal never received.
 		Healthy() bool
 
+		// Observe
+		// register observer on process and subprocesses, it is
+		// inherited by subprocesses added later.
+		Observe(ob Observer) Processor
+
 		// Name
 		// return process name.
 		//
@@ -73,18 +87,66 @@ type (
 		// register panic event.
 		Panic(cp PanicEvent) Processor
 
+		// Persist
+		// config json file of store.
+		//
+		// Store is restored from file before calling before
+		// events, saved into file on each Set and Del while
+		// process running, and saved again after after events
+		// called.
+		Persist(path string) Processor
+
+		// Propagate
+		// config how process reacts when any subprocess failed.
+		//
+		// Subprocess failure is ignored by default. Otherwise
+		// the failure stops siblings or this process, and Start
+		// return a *GroupError names every failed subprocess.
+		Propagate(pp Propagation) Processor
+
 		// Restart process.
 		Restart()
 
+		// RestartTagged
+		// restart process and subprocesses with tag, return
+		// count of restarted processes.
+		//
+		// Subprocesses of restarted process are skipped, they
+		// are restarted with their parent.
+		RestartTagged(tag string) int
+
+		// Snapshot
+		// return state tree of process and subprocesses.
+		Snapshot() *Snapshot
+
+		// SnapshotTagged
+		// return snapshots of process and subprocesses with tag
+		// in depth first order.
+		SnapshotTagged(tag string) []*Snapshot
+
 		// Start process.
 		//
 		// Return error if started already or is starting or is
 		// stopping or is restarting.
 		Start(ctx context.Context) error
 
+		// StartAsync
+		// start process in goroutine and return handle of it.
+		//
+		//   h := proc.StartAsync(ctx)
+		//   <-h.Started()
+		//   if err := h.Err(); err != nil {
+		//       return err
+		//   }
+		StartAsync(ctx context.Context) *Handle
+
 		// StartChild start subprocess.
 		StartChild(name string) error
 
+		// State
+		// return lifetime state of process.
+		State() State
+
 		// Stop process.
 		Stop()
 
@@ -93,10 +155,49 @@ type (
 		// never start.
 		Stopped() bool
 
+		// Store
+		// return keyed state store of process, it survives
+		// restarts and is reachable from event context by
+		// StoreFrom.
+		Store() *Store
+
+		// StopTagged
+		// stop process and subprocesses with tag, return count
+		// of stopped processes.
+		//
+		// Subprocesses of stopped process are skipped, they are
+		// stopped with their parent.
+		StopTagged(tag string) int
+
+		// Tag
+		// add tags on process, tags group processes across the
+		// process tree.
+		//
+		//   proc.Tag("io", "consumer")
+		Tag(tags ...string) Processor
+
+		// Tags
+		// return sorted tags of process.
+		Tags() []string
+
+		// Trace
+		// config tracer of process and subprocesses, it is
+		// inherited by subprocesses added later.
+		//
+		// Each run, phase and restart of process produce a
+		// span, spans of subprocesses are children of span of
+		// main events of parent.
+		Trace(t Tracer) Processor
+
 		// Unbind
 		// call parent process delete child.
 		Unbind() Processor
 
+		// UseClock
+		// config clock of process and subprocesses, it is
+		// inherited by subprocesses added later.
+		UseClock(c Clock) Processor
+
 		// UnbindWhenStopped
 		// config process unbind type.
 		//
@@ -113,18 +214,71 @@ type (
 		cancel context.CancelFunc
 		ctx    context.Context
 
-		mu            sync.RWMutex
-		name          string
-		running, redo bool
+		mu                    sync.RWMutex
+		name                  string
+		manual, running, redo bool
 
 		ae, be, ce     []Event
 		pe             PanicEvent
 		parent         Processor
 		subprocesses   map[string]Processor
 		unbindWhenStop bool
+
+		clock     Clock
+		failures  []*ChildError
+		idle      *sync.Cond
+		observer  Observer
+		pending   int
+		propagate Propagation
+		restarts  int
+		startedAt time.Time
+		state     State
+		store     *Store
+		storePath string
+		tags      map[string]bool
+		tracer    Tracer
 	}
 )
 
+const (
+	// PropagateNone
+	// ignore subprocess failure.
+	PropagateNone Propagation = "none"
+
+	// PropagateSiblings
+	// stop other subprocesses if any subprocess failed.
+	PropagateSiblings Propagation = "siblings"
+
+	// PropagateParent
+	// stop process and all subprocesses if any subprocess
+	// failed.
+	PropagateParent Propagation = "parent"
+)
+
+const (
+	// StateStarting
+	// process is calling before events.
+	StateStarting State = "starting"
+
+	// StateRunning
+	// process is calling main events.
+	StateRunning State = "running"
+
+	// StateRestarting
+	// process is waiting subprocesses stopped then call main
+	// events again.
+	StateRestarting State = "restarting"
+
+	// StateStopping
+	// process is waiting subprocesses stopped or calling after
+	// events.
+	StateStopping State = "stopping"
+
+	// StateStopped
+	// process never started or stopped already.
+	StateStopped State = "stopped"
+)
+
 // New
 // create and return processor interface.
 //
@@ -140,22 +294,36 @@ func New(name string) Processor {
 // /////////////////////////////////////////////////////////////
 
 func (o *processor) Add(ps ...Processor) Processor                    { return o.add(ps) }
-func (o *processor) After(cs ...Event) Processor                      { o.ae = cs; return o }
-func (o *processor) Before(cs ...Event) Processor                     { o.be = cs; return o }
-func (o *processor) Callback(cs ...Event) Processor                   { o.ce = cs; return o }
+func (o *processor) After(cs ...Event) Processor                      { return o.setEvents(&o.ae, cs) }
+func (o *processor) Before(cs ...Event) Processor                     { return o.setEvents(&o.be, cs) }
+func (o *processor) Callback(cs ...Event) Processor                   { return o.setEvents(&o.ce, cs) }
 func (o *processor) Del(ps ...Processor) Processor                    { return o.del(ps) }
 func (o *processor) Get(name string) (process Processor, exists bool) { return o.get(name) }
 func (o *processor) GetParent() (process Processor)                   { return o.getParent() }
 func (o *processor) Healthy() bool                                    { return o.healthy() }
 func (o *processor) Name() string                                     { return o.name }
-func (o *processor) Panic(cp PanicEvent) Processor                    { o.pe = cp; return o }
+func (o *processor) Observe(ob Observer) Processor                    { return o.setObserver(ob) }
+func (o *processor) Panic(cp PanicEvent) Processor                    { return o.setPanic(cp) }
+func (o *processor) Persist(path string) Processor                    { return o.setPersist(path) }
+func (o *processor) Propagate(pp Propagation) Processor               { return o.setPropagate(pp) }
 func (o *processor) Restart()                                         { o.restart() }
-func (o *processor) Start(ctx context.Context) error                  { return o.start(ctx) }
+func (o *processor) RestartTagged(tag string) int                     { return o.restartTagged(tag) }
+func (o *processor) Snapshot() *Snapshot                              { return o.snapshot() }
+func (o *processor) SnapshotTagged(tag string) []*Snapshot            { return o.snapshotTagged(tag) }
+func (o *processor) Start(ctx context.Context) error                  { return o.start(ctx, nil) }
+func (o *processor) StartAsync(ctx context.Context) *Handle           { return o.startAsync(ctx) }
 func (o *processor) StartChild(name string) error                     { return o.startChild(name) }
+func (o *processor) State() State                                     { return o.getState() }
 func (o *processor) Stop()                                            { o.stop() }
 func (o *processor) Stopped() bool                                    { return o.stopped() }
+func (o *processor) StopTagged(tag string) int                        { return o.stopTagged(tag) }
+func (o *processor) Store() *Store                                    { return o.store }
+func (o *processor) Tag(tags ...string) Processor                     { return o.tag(tags) }
+func (o *processor) Tags() []string                                   { return o.getTags() }
+func (o *processor) Trace(t Tracer) Processor                         { return o.setTracer(t) }
 func (o *processor) Unbind() Processor                                { return o.unbind() }
-func (o *processor) UnbindWhenStopped(b bool) Processor               { o.unbindWhenStop = b; return o }
+func (o *processor) UseClock(c Clock) Processor                       { return o.setClock(c) }
+func (o *processor) UnbindWhenStopped(b bool) Processor               { return o.setUnbindWhenStopped(b) }
 
 // /////////////////////////////////////////////////////////////
 // Access methods.
@@ -171,6 +339,18 @@ func (o *processor) add(ps []Processor) Processor {
 		if _, ok := o.subprocesses[p.Name()]; ok {
 			continue
 		}
+
+		// Inherit clock and observer.
+		if o.clock != DefaultClock {
+			p.UseClock(o.clock)
+		}
+		if o.observer != nil {
+			p.Observe(o.observer)
+		}
+		if o.tracer != nil {
+			p.Trace(o.tracer)
+		}
+
 		o.subprocesses[p.Name()] = p.bind(o)
 	}
 	return o
@@ -208,6 +388,22 @@ func (o *processor) get(name string) (process Processor, exists bool) {
 	return
 }
 
+// Clock
+// return clock of process.
+func (o *processor) getClock() Clock {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.clock
+}
+
+// State
+// return lifetime state of process.
+func (o *processor) getState() State {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.state
+}
+
 // GetParent
 // return parent process.
 func (o *processor) getParent() (process Processor) {
@@ -216,6 +412,14 @@ func (o *processor) getParent() (process Processor) {
 	return o.parent
 }
 
+// Events
+// return registered events of target.
+func (o *processor) events(target *[]Event) []Event {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return *target
+}
+
 // Healthy
 // return process health status.
 func (o *processor) healthy() bool {
@@ -226,8 +430,14 @@ func (o *processor) healthy() bool {
 
 func (o *processor) init() *processor {
 	o.subprocesses = make(map[string]Processor)
+	o.tags = make(map[string]bool)
+	o.store = NewStore()
 	o.mu = sync.RWMutex{}
+	o.idle = sync.NewCond(&o.mu)
 	o.unbindWhenStop = false
+	o.clock = DefaultClock
+	o.propagate = PropagateNone
+	o.state = StateStopped
 	o.initState()
 	return o
 }
@@ -237,18 +447,129 @@ func (o *processor) initState() {
 	o.running = false
 }
 
+// UseClock
+// config clock of process and subprocesses.
+func (o *processor) setClock(c Clock) Processor {
+	o.mu.Lock()
+	o.clock = c
+	o.mu.Unlock()
+
+	for _, child := range o.doChildren() {
+		child.UseClock(c)
+	}
+	return o
+}
+
+// Trace
+// config tracer of process and subprocesses.
+func (o *processor) setTracer(t Tracer) Processor {
+	o.mu.Lock()
+	o.tracer = t
+	o.mu.Unlock()
+
+	for _, child := range o.doChildren() {
+		child.Trace(t)
+	}
+	return o
+}
+
+// Observe
+// register observer on process and subprocesses.
+func (o *processor) setObserver(ob Observer) Processor {
+	o.mu.Lock()
+	o.observer = ob
+	o.mu.Unlock()
+
+	for _, child := range o.doChildren() {
+		child.Observe(ob)
+	}
+	return o
+}
+
+// State
+// change lifetime state of process and notify observer.
+func (o *processor) setState(to State) {
+	o.mu.Lock()
+	from := o.state
+	switch {
+	case to == StateStarting:
+		o.restarts = 0
+		o.startedAt = o.clock.Now()
+	case to == StateRunning && from == StateRestarting:
+		o.restarts++
+	}
+	o.state = to
+	ob := o.observer
+	o.mu.Unlock()
+
+	if ob != nil && from != to {
+		ob(o, from, to)
+	}
+}
+
+// Events
+// replace registered events of target, target is one of
+// after, before or main events.
+func (o *processor) setEvents(target *[]Event, es []Event) Processor {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	*target = es
+	return o
+}
+
+// Panic
+// register panic event.
+func (o *processor) setPanic(pe PanicEvent) Processor {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pe = pe
+	return o
+}
+
+// UnbindWhenStopped
+// config process unbind type.
+func (o *processor) setUnbindWhenStopped(b bool) Processor {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.unbindWhenStop = b
+	return o
+}
+
+// Persist
+// config json file of store.
+func (o *processor) setPersist(path string) Processor {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.storePath = path
+	return o
+}
+
+// Propagate
+// config how process reacts when any subprocess failed.
+func (o *processor) setPropagate(pp Propagation) Processor {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.propagate = pp
+	return o
+}
+
 // Restart process.
 func (o *processor) restart() {
-	if o.healthy() {
-		o.mu.Lock()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.ctx != nil && o.ctx.Err() == nil {
 		o.redo = true
-		o.mu.Unlock()
 		o.cancel()
 	}
 }
 
 // Start process.
-func (o *processor) start(ctx context.Context) (err error) {
+//
+// Handle is resolved when main events begin, or finished with
+// the result when process returned, handle may be nil.
+func (o *processor) start(ctx context.Context, h *Handle) (err error) {
+	defer func() { h.finish(o.name, err) }()
+
 	o.mu.Lock()
 
 	// Return repeat running error.
@@ -259,29 +580,87 @@ func (o *processor) start(ctx context.Context) (err error) {
 
 	// Set process status as running
 	o.running = true
+	o.failures = nil
 	o.mu.Unlock()
+	o.setState(StateStarting)
 
 	// Set process status as stopped.
 	defer func() {
 		// Delete from parent.
-		if o.unbindWhenStop && o.parent != nil {
-			o.parent.Del(o)
+		o.mu.RLock()
+		parent, unbind := o.parent, o.unbindWhenStop
+		o.mu.RUnlock()
+		if unbind && parent != nil {
+			parent.Del(o)
 		}
 
 		o.mu.Lock()
 		o.initState()
 		o.mu.Unlock()
+		o.setState(StateStopped)
+	}()
+
+	// Trace run of process.
+	ctx, rs := o.startSpan(ctx, SpanRun)
+	defer func() { rs.End(err) }()
+
+	// Override result with failed subprocesses, called after
+	// after events.
+	defer func() {
+		o.mu.RLock()
+		defer o.mu.RUnlock()
+		if len(o.failures) > 0 {
+			err = &GroupError{Name: o.name, Err: err, Children: o.failures}
+		}
 	}()
 
+	// Restore store, save it on each change while running and
+	// after after events called.
+	o.mu.RLock()
+	storePath := o.storePath
+	o.mu.RUnlock()
+	if storePath != "" {
+		if se := o.store.Load(storePath); se != nil {
+			return fmt.Errorf("process '%s' restore store: %v", o.name, se)
+		}
+		o.store.bind(storePath)
+		defer func() {
+			o.store.bind("")
+			if se := o.store.Save(storePath); se != nil && err == nil {
+				err = fmt.Errorf("process '%s' save store: %v", o.name, se)
+			}
+		}()
+	}
+
+	// Events context carry store of process.
+	ctx = withStore(ctx, o.store)
+
 	// Call before events.
-	if ci, ce := o.doHandlers(ctx, o.be); ci {
+	var (
+		ci     bool
+		ce     error
+		bc, bs = o.startSpan(ctx, SpanBefore)
+	)
+	o.doLabeled(bc, PhaseBefore, func(c context.Context) {
+		ci, ce = o.doHandlers(c, o.events(&o.be))
+	})
+	if bs.End(ce); ci {
 		return ce
 	}
 
 	// Call after events, override result if error returned by
 	// any event.
 	defer func(c context.Context) {
-		if _, ce := o.doHandlers(c, o.ae); ce != nil && err == nil {
+		o.setState(StateStopping)
+
+		var (
+			ce     error
+			ac, as = o.startSpan(c, SpanAfter)
+		)
+		o.doLabeled(ac, PhaseAfter, func(c context.Context) {
+			_, ce = o.doHandlers(c, o.events(&o.ae))
+		})
+		if as.End(ce); ce != nil && err == nil {
 			err = ce
 			return
 		}
@@ -289,7 +668,7 @@ func (o *processor) start(ctx context.Context) (err error) {
 
 	// Loop call main handlers until process stop signal
 	// received.
-	for {
+	for loops := 0; ; loops++ {
 		// Return
 		// for parent context cancelled.
 		if ctx == nil || ctx.Err() != nil {
@@ -297,32 +676,50 @@ func (o *processor) start(ctx context.Context) (err error) {
 		}
 
 		// Return
-		// for stop signal received.
-		if !func() (re bool) {
+		// for stop signal received, otherwise build process
+		// context. Signal checking and context building must
+		// be atomic, or stop signal may be lost.
+		c, cc := func() (c context.Context, cc context.CancelFunc) {
 			o.mu.Lock()
 			defer o.mu.Unlock()
-			if re = o.redo; re {
+			if o.redo {
 				o.redo = false
+				c, cc = context.WithCancel(ctx)
+				o.ctx, o.cancel = c, cc
 			}
 			return
-		}() {
+		}()
+		if c == nil {
 			return
 		}
+		o.setState(StateRunning)
 
-		// Build process context.
-		o.mu.Lock()
-		o.ctx, o.cancel = context.WithCancel(ctx)
-		o.mu.Unlock()
+		// Trace restart and main handlers, subprocesses are
+		// traced as children of main handlers.
+		var ts Span = noopSpan{}
+		if loops > 0 {
+			c, ts = o.startSpan(c, SpanRestart)
+		}
+		c, cs := o.startSpan(c, SpanCallback)
 
 		// Start children.
-		o.doChildStart(o.ctx)
+		o.doChildStart(c)
 
-		// Call main handlers.
-		err = func(c context.Context, cc context.CancelFunc) error {
+		// Call main handlers, startup succeed.
+		h.resolve(nil)
+		o.doLabeled(c, PhaseCallback, func(c context.Context) {
 			defer cc()
-			_, ce := o.doHandlers(c, o.ce)
-			return ce
-		}(o.ctx, o.cancel)
+			_, err = o.doHandlers(c, o.events(&o.ce))
+		})
+		cs.End(err)
+
+		if o.mu.RLock(); o.redo {
+			o.mu.RUnlock()
+			o.setState(StateRestarting)
+		} else {
+			o.mu.RUnlock()
+			o.setState(StateStopping)
+		}
 
 		// Stop subprocesses, block coroutine until all
 		// subprocesses stopped.
@@ -333,13 +730,22 @@ func (o *processor) start(ctx context.Context) (err error) {
 		o.ctx = nil
 		o.cancel = nil
 		o.mu.Unlock()
+		ts.End(nil)
 	}
 }
 
+// StartAsync
+// start process in goroutine.
+func (o *processor) startAsync(ctx context.Context) *Handle {
+	h := newHandle()
+	go func() { _ = o.start(ctx, h) }()
+	return h
+}
+
 // startChild start subprocess.
 func (o *processor) startChild(name string) (err error) {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
+	o.mu.Lock()
+	defer o.mu.Unlock()
 
 	p, exists := o.subprocesses[name]
 	if !exists {
@@ -352,20 +758,48 @@ func (o *processor) startChild(name string) (err error) {
 		return
 	}
 
-	go func() { _ = p.Start(o.ctx) }()
+	if o.ctx == nil || o.ctx.Err() != nil {
+		err = fmt.Errorf("process '%s' not running", o.name)
+		return
+	}
+
+	o.pending++
+	go func(c context.Context) {
+		doChildLabels(c, p)
+		o.doChildFailed(p, p.Start(c))
+	}(o.ctx)
 	return
 }
 
 // Stop process.
+//
+// Stop signal is kept if process is calling before events or
+// restarting, process returns before calling main events.
 func (o *processor) stop() {
-	if o.healthy() {
-		o.mu.Lock()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.running {
 		o.redo = false
-		o.mu.Unlock()
+	}
+	if o.cancel != nil {
 		o.cancel()
 	}
 }
 
+// Span
+// start span of process, return noop span if tracer not
+// configured.
+func (o *processor) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	o.mu.RLock()
+	t := o.tracer
+	o.mu.RUnlock()
+
+	if t == nil || ctx == nil {
+		return ctx, noopSpan{}
+	}
+	return t.Start(ctx, name, map[string]string{"process": Path(o)})
+}
+
 // Stopped
 // return process stopped status.
 func (o *processor) stopped() bool {
@@ -375,10 +809,10 @@ func (o *processor) stopped() bool {
 }
 
 func (o *processor) unbind() *processor {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
-	if o.parent != nil {
-		o.parent.Del(o)
+	// Release lock before calling parent, parent may lock
+	// itself then this process.
+	if parent := o.getParent(); parent != nil {
+		parent.Del(o)
 	}
 	return o
 }
@@ -387,48 +821,108 @@ func (o *processor) unbind() *processor {
 // lifetime method.
 // /////////////////////////////////////////////////////////////
 
+func (o *processor) doChildFailed(p Processor, err error) {
+	o.mu.Lock()
+	if o.pending--; o.pending == 0 {
+		o.idle.Broadcast()
+	}
+
+	// Ignore succeed subprocess, or failure if propagation
+	// not configured.
+	if err == nil || o.propagate == PropagateNone {
+		o.mu.Unlock()
+		return
+	}
+
+	o.failures = append(o.failures, &ChildError{Name: p.Name(), Err: err})
+	pp := o.propagate
+	o.mu.Unlock()
+
+	switch pp {
+	case PropagateParent:
+		o.stop()
+	case PropagateSiblings:
+		for _, child := range o.doChildren() {
+			if child != p {
+				child.Stop()
+			}
+		}
+	}
+}
+
+// Labels
+// set pprof labels of subprocess on goroutine which starts
+// subprocess.
+func doChildLabels(ctx context.Context, p Processor) {
+	if ctx != nil {
+		pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(LabelProcess, Path(p), LabelPhase, PhaseStart)))
+	}
+}
+
+func (o *processor) doChildren() (children []Processor) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for _, child := range o.subprocesses {
+		children = append(children, child)
+	}
+	return
+}
+
 func (o *processor) doChildStart(ctx context.Context) {
-	for _, child := range func() map[string]Processor {
-		o.mu.RLock()
-		defer o.mu.RUnlock()
-		return o.subprocesses
-	}() {
+	if o.manual {
+		return
+	}
+
+	// Iterate copy of subprocesses, subprocesses may be added
+	// or deleted concurrently.
+	for _, child := range o.doChildren() {
 		if child.Stopped() {
+			o.mu.Lock()
+			o.pending++
+			o.mu.Unlock()
+
 			go func(c context.Context, p Processor) {
-				_ = p.Start(c)
+				doChildLabels(c, p)
+				o.doChildFailed(p, p.Start(c))
 			}(ctx, child)
 		}
 	}
 }
 
 func (o *processor) doChildStopped() (stopped bool) {
-	for _, child := range func() map[string]Processor {
-		o.mu.RLock()
-		defer o.mu.RUnlock()
-		return o.subprocesses
-	}() {
-		if child.Stopped() {
-			continue
-		}
-
-		if child.Healthy() {
-			child.Stopped()
-		}
+	o.mu.Lock()
+	defer o.mu.Unlock()
 
-		time.Sleep(time.Millisecond)
-		return o.doChildStopped()
+	// Block until all started subprocesses returned and
+	// failures collected.
+	for o.pending > 0 {
+		o.idle.Wait()
 	}
 	return true
 }
 
+// Labeled
+// call fn with pprof labels of process and phase, goroutines
+// started by fn inherit the labels.
+func (o *processor) doLabeled(ctx context.Context, phase string, fn func(c context.Context)) {
+	if ctx == nil {
+		fn(ctx)
+		return
+	}
+	pprof.Do(ctx, pprof.Labels(LabelProcess, Path(o), LabelPhase, phase), fn)
+}
+
 func (o *processor) doHandlers(ctx context.Context, handlers []Event) (ignored bool, err error) {
 	defer func() {
 		if v := recover(); v != nil {
 			err = fmt.Errorf("%v", v)
 			ignored = true
 
-			if o.pe != nil {
-				o.pe(ctx, v)
+			o.mu.RLock()
+			pe := o.pe
+			o.mu.RUnlock()
+			if pe != nil {
+				pe(ctx, v)
 			}
 		}
 	}()
@@ -1,109 +1,186 @@
 // author: wsfuyibing <websearch@163.com>
 // date: 2023-02-20
 
-package process
+package process_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
-	"time"
-)
 
-var (
-	rootCtx, rootCanceler = context.WithCancel(context.Background())
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
 )
 
 func TestNew(t *testing.T) {
-	// Cancel by root context after 1 second..
-	go func() {
-		time.Sleep(time.Second)
-		t.Logf("------------- root cancel")
-		rootCanceler()
-	}()
-
-	m := (&my{t: t, name: "p1"}).init()
-	if err := m.processor.Start(rootCtx); err != nil {
-		t.Logf("%s stopped: %v", m.processor.Name(), err)
+	m := (&my{name: "p1"}).init()
+	h := processtest.New(t, m.processor)
+
+	h.Start()
+	if err := h.Stop(); err != nil {
+		t.Fatalf("p1 stopped: %v", err)
 	}
+
+	m.expect(t, 1, 1, 1)
+	expectTransitions(t, h, "p1",
+		process.StateStarting, process.StateRunning, process.StateStopping, process.StateStopped,
+	)
 }
 
 func TestProcessor_Add(t *testing.T) {
-	var m = (&my{t: t, name: "p1"}).init()
+	var (
+		m  = (&my{name: "p1"}).init()
+		c1 = (&my{name: "c1"}).init()
+		c2 = (&my{name: "c2"}).init()
+		c3 = (&my{name: "c3"}).init()
+	)
+
+	m.processor.Add(c1.processor, c2.processor.UnbindWhenStopped(true), c3.processor)
+
+	h := processtest.New(t, m.processor)
+	h.Start()
+	h.ExpectState("p1/c1", process.StateRunning)
+	h.ExpectState("p1/c2", process.StateRunning)
+	h.ExpectState("p1/c3", process.StateRunning)
+
+	// Restart subprocess.
+	h.Restart("p1/c1")
+	c1.expect(t, 1, 2, 0)
+	expectTransitions(t, h, "p1/c1",
+		process.StateStarting, process.StateRunning, process.StateRestarting, process.StateRunning,
+	)
+
+	// Stop subprocess, unbind from parent.
+	h.StopChild("p1/c2")
+	if _, exists := m.processor.Get("c2"); exists {
+		t.Fatalf("c2 should be unbound when stopped")
+	}
 
-	// Cancel by root context after 3 second..
-	go func() {
+	// Stop and start subprocess again.
+	h.StopChild("p1/c3")
+	h.StartChild("p1/c3")
+	c3.expect(t, 2, 2, 1)
 
-		for _, name := range []string{"c1", "c2", "c3"} {
-			time.Sleep(time.Second)
+	// Restart parent, restart subprocesses too.
+	h.Restart("p1")
+	h.ExpectState("p1/c1", process.StateRunning)
+	h.ExpectState("p1/c3", process.StateRunning)
 
-			if child, exists := m.processor.Get(name); exists {
-				t.Logf("---- [child=%s] restart", name)
-				child.Restart()
-			} else {
-				t.Logf("---- [child=%s] not found", name)
-			}
+	if err := h.Stop(); err != nil {
+		t.Fatalf("p1 stopped: %v", err)
+	}
+
+	m.expect(t, 1, 2, 1)
+	c1.expect(t, 2, 3, 2)
+	c2.expect(t, 1, 1, 1)
+	c3.expect(t, 3, 3, 3)
+}
+
+func TestProcessor_Propagate(t *testing.T) {
+	var (
+		fail  = make(chan struct{})
+		block = func(ctx context.Context) (ignored bool) {
+			<-ctx.Done()
+			return
 		}
+	)
+
+	failure := func(ctx context.Context) (ignored bool) {
+		<-fail
+		panic("boom")
+	}
+
+	// Stop parent.
+	root := process.New("root").Propagate(process.PropagateParent).Callback(block).Add(
+		process.New("c1").Callback(failure),
+		process.New("c2").Callback(block),
+	)
 
-		time.Sleep(time.Second * 2)
-		t.Logf("---- ---- [root] restart")
-		m.processor.Restart()
+	h := processtest.New(t, root)
+	h.Start()
+	h.ExpectState("root/c1", process.StateRunning)
+	close(fail)
 
-		time.Sleep(time.Second * 3)
-		t.Logf("---- ---- [root] cancel context")
-		rootCanceler()
-	}()
+	err := h.Wait()
+	ge, ok := err.(*process.GroupError)
+	if !ok {
+		t.Fatalf("expect group error, got %v", err)
+	}
+	if len(ge.Children) != 1 || ge.Children[0].Name != "c1" || ge.Children[0].Err.Error() != "boom" {
+		t.Fatalf("unexpected group error: %v", ge)
+	}
 
-	m = (&my{t: t, name: "p1"}).init()
-	m.processor.Add(
-		(&my{t: t, name: "c1"}).init().processor,
-		(&my{t: t, name: "c2"}).init().processor.UnbindWhenStopped(true),
-		(&my{t: t, name: "c3"}).init().processor,
+	// Stop siblings only.
+	fail = make(chan struct{})
+	root = process.New("root").Propagate(process.PropagateSiblings).Callback(block).Add(
+		process.New("c1").Callback(failure),
+		process.New("c2").Callback(block),
 	)
 
-	if err := m.processor.Start(rootCtx); err != nil {
-		t.Logf("%s stopped: %v", m.processor.Name(), err)
+	h = processtest.New(t, root)
+	h.Start()
+	h.ExpectState("root/c2", process.StateRunning)
+	close(fail)
+
+	h.ExpectState("root/c1", process.StateStopped)
+	h.ExpectState("root/c2", process.StateStopped)
+	h.ExpectState("root", process.StateRunning)
+
+	if err = h.Stop(); err == nil || err.Error() != "process 'root': subprocess 'c1': boom" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func expectTransitions(t *testing.T, h *processtest.Harness, path string, states ...process.State) {
+	t.Helper()
+
+	list := h.Transitions(path)
+	if len(list) < len(states) {
+		t.Fatalf("%s expect %d transitions, got %v", path, len(states), list)
+	}
+	for i, state := range states {
+		if list[i].To != state {
+			t.Fatalf("%s transition %d expect %s, got %s", path, i, state, list[i].To)
+		}
 	}
 }
 
 // My struct for processor.
 
 type my struct {
-	name      string
-	processor Processor
-	t         *testing.T
+	after, before, call int32
+	name                string
+	processor           process.Processor
+}
+
+func (o *my) expect(t *testing.T, before, call, after int32) {
+	t.Helper()
+
+	if b, c, a := atomic.LoadInt32(&o.before), atomic.LoadInt32(&o.call), atomic.LoadInt32(&o.after); b != before || c != call || a != after {
+		t.Fatalf("%s expect before=%d, call=%d, after=%d, got before=%d, call=%d, after=%d", o.name, before, call, after, b, c, a)
+	}
 }
 
 func (o *my) init() *my {
-	o.processor = New(o.name).
+	o.processor = process.New(o.name).
 		After(o.onAfter).
 		Before(o.onBefore).
-		Callback(o.onCall).
-		Panic(o.onPanic)
+		Callback(o.onCall)
 	return o
 }
 
 func (o *my) onAfter(_ context.Context) (ignored bool) {
-	o.t.Logf("%s after", o.processor.Name())
+	atomic.AddInt32(&o.after, 1)
 	return
 }
 
 func (o *my) onBefore(_ context.Context) (ignored bool) {
-	o.t.Logf("%s before", o.processor.Name())
+	atomic.AddInt32(&o.before, 1)
 	return
 }
 
 func (o *my) onCall(ctx context.Context) (ignored bool) {
-	o.t.Logf("%s call", o.processor.Name())
-
-	for {
-		select {
-		case <-ctx.Done():
-			o.t.Logf("%s call: %v", o.processor.Name(), ctx.Err())
-			return
-		}
-	}
-}
-
-func (o *my) onPanic(_ context.Context, v interface{}) {
-	o.t.Logf("%s panic: %v", o.processor.Name(), v)
+	atomic.AddInt32(&o.call, 1)
+	<-ctx.Done()
+	return
 }
@@ -0,0 +1,99 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package processtest
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Clock
+	// fake clock which only moves forward when Advance called.
+	Clock struct {
+		cond    *sync.Cond
+		mu      sync.Mutex
+		now     time.Time
+		waiters []*clockWaiter
+	}
+
+	clockWaiter struct {
+		ch       chan time.Time
+		deadline time.Time
+	}
+)
+
+// NewClock
+// create and return fake clock starts at specified time.
+func NewClock(now time.Time) *Clock {
+	o := &Clock{now: now}
+	o.cond = sync.NewCond(&o.mu)
+	return o
+}
+
+// Advance
+// move clock forward and fire waiters whose deadline reached.
+func (o *Clock) Advance(d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.now = o.now.Add(d)
+
+	waiters := o.waiters[:0]
+	for _, w := range o.waiters {
+		if w.deadline.After(o.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- o.now
+	}
+	o.waiters = waiters
+}
+
+// After
+// return channel which receive time when clock advanced over
+// duration.
+func (o *Clock) After(d time.Duration) <-chan time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	w := &clockWaiter{ch: make(chan time.Time, 1), deadline: o.now.Add(d)}
+	if d <= 0 {
+		w.ch <- o.now
+		return w.ch
+	}
+
+	o.waiters = append(o.waiters, w)
+	o.cond.Broadcast()
+	return w.ch
+}
+
+// BlockUntil
+// block until n waiters registered by After.
+//
+// Call it before Advance to make sure the goroutine under test
+// is waiting on clock.
+func (o *Clock) BlockUntil(n int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for len(o.waiters) < n {
+		o.cond.Wait()
+	}
+}
+
+// Now
+// return current time of clock.
+func (o *Clock) Now() time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.now
+}
+
+// Waiters
+// return count of waiters not fired.
+func (o *Clock) Waiters() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.waiters)
+}
@@ -0,0 +1,302 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+// Package processtest
+// deterministic test harness for processors.
+//
+//   h := processtest.New(t, root)
+//   h.Start()
+//   h.ExpectState("root/consumer", process.StateRunning)
+//   h.Restart("root/consumer")
+//   if err := h.Stop(); err != nil {
+//       t.Fatal(err)
+//   }
+package processtest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fuyibing/util/v8/process"
+)
+
+const (
+	// DefaultTimeout
+	// of waiting for any expectation, the harness fails test
+	// rather than hangs forever.
+	DefaultTimeout = time.Second * 5
+)
+
+type (
+	// Harness
+	// drive and observe processor tree in tests.
+	Harness struct {
+		Clock   *Clock
+		Timeout time.Duration
+
+		cancel      context.CancelFunc
+		cond        *sync.Cond
+		done        chan struct{}
+		err         error
+		goroutines  int
+		mu          sync.Mutex
+		root        process.Processor
+		t           testing.TB
+		transitions []Transition
+	}
+
+	// Transition
+	// lifetime state change of process.
+	Transition struct {
+		Path     string
+		From, To process.State
+		Time     time.Time
+	}
+)
+
+// New
+// create and return harness for root processor.
+//
+// Fake clock and observer are injected into root and all
+// subprocesses, goroutines count is recorded for leak check.
+func New(t testing.TB, root process.Processor) *Harness {
+	h := &Harness{
+		Clock:      NewClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Timeout:    DefaultTimeout,
+		goroutines: runtime.NumGoroutine(),
+		root:       root,
+		t:          t,
+	}
+	h.cond = sync.NewCond(&h.mu)
+	root.UseClock(h.Clock).Observe(h.observe)
+	return h
+}
+
+// /////////////////////////////////////////////////////////////
+// Step methods.
+// /////////////////////////////////////////////////////////////
+
+// Start
+// start root processor in goroutine and block until it is
+// running.
+func (h *Harness) Start() {
+	h.t.Helper()
+
+	var ctx context.Context
+	ctx, h.cancel = context.WithCancel(context.Background())
+	h.done = make(chan struct{})
+
+	go func() {
+		err := h.root.Start(ctx)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.err = err
+		close(h.done)
+		h.cond.Broadcast()
+	}()
+
+	h.ExpectState(h.root.Name(), process.StateRunning)
+}
+
+// Restart
+// restart process of path and block until it is running again.
+func (h *Harness) Restart(path string) {
+	h.t.Helper()
+
+	p := h.find(path)
+	n := h.snapshot(path).Restarts
+	p.Restart()
+
+	h.wait(fmt.Sprintf("%s restarted", path), func() bool {
+		s := h.snapshot(path)
+		return s != nil && s.Restarts > n && s.State == process.StateRunning
+	})
+}
+
+// StartChild
+// start subprocess of path and block until it is running.
+func (h *Harness) StartChild(path string) {
+	h.t.Helper()
+
+	p := h.find(path)
+	if err := p.GetParent().StartChild(p.Name()); err != nil {
+		h.t.Fatalf("processtest: start %s: %v", path, err)
+	}
+	h.ExpectState(path, process.StateRunning)
+}
+
+// StopChild
+// stop process of path and block until it is stopped.
+func (h *Harness) StopChild(path string) {
+	h.t.Helper()
+
+	// Process may be unbound from parent when stopped, so wait
+	// on process rather than path.
+	p := h.find(path)
+	p.Stop()
+	h.wait(fmt.Sprintf("%s %s", path, process.StateStopped), func() bool {
+		return p.State() == process.StateStopped
+	})
+}
+
+// Stop
+// stop root processor, block until Start returned and check
+// goroutines leak, return error of Start.
+func (h *Harness) Stop() error {
+	h.t.Helper()
+
+	h.root.Stop()
+	return h.Wait()
+}
+
+// Wait
+// block until Start of root processor returned and check
+// goroutines leak, return error of Start.
+func (h *Harness) Wait() error {
+	h.t.Helper()
+
+	h.wait("root returned", func() bool {
+		select {
+		case <-h.done:
+			return true
+		default:
+			return false
+		}
+	})
+
+	h.cancel()
+	h.CheckLeak()
+	return h.err
+}
+
+// /////////////////////////////////////////////////////////////
+// Assert methods.
+// /////////////////////////////////////////////////////////////
+
+// CheckLeak
+// fail test if goroutines started after harness created are
+// still alive.
+func (h *Harness) CheckLeak() {
+	h.t.Helper()
+
+	deadline := time.Now().Add(h.Timeout)
+	for runtime.NumGoroutine() > h.goroutines {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			buf = buf[:runtime.Stack(buf, true)]
+			h.t.Fatalf("processtest: %d goroutines leaked:\n%s", runtime.NumGoroutine()-h.goroutines, buf)
+		}
+		runtime.Gosched()
+	}
+}
+
+// Expect
+// block until condition on snapshot of root processor met,
+// condition is checked each time any transition recorded.
+func (h *Harness) Expect(desc string, condition func(root *process.Snapshot) bool) {
+	h.t.Helper()
+
+	h.wait(desc, func() bool {
+		return condition(h.root.Snapshot())
+	})
+}
+
+// ExpectState
+// block until process of path reached state.
+func (h *Harness) ExpectState(path string, state process.State) {
+	h.t.Helper()
+
+	h.wait(fmt.Sprintf("%s %s", path, state), func() bool {
+		s := h.snapshot(path)
+		return s != nil && s.State == state
+	})
+}
+
+// Transitions
+// return recorded state changes of process path, return all
+// changes if path is empty.
+func (h *Harness) Transitions(path string) []Transition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list := make([]Transition, 0)
+	for _, x := range h.transitions {
+		if path == "" || x.Path == path {
+			list = append(list, x)
+		}
+	}
+	return list
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal methods.
+// /////////////////////////////////////////////////////////////
+
+func (h *Harness) find(path string) process.Processor {
+	h.t.Helper()
+
+	names := strings.Split(path, process.PathSeparator)
+	if names[0] != h.root.Name() {
+		h.t.Fatalf("processtest: process %s not found", path)
+	}
+
+	p := h.root
+	for _, name := range names[1:] {
+		child, exists := p.Get(name)
+		if !exists {
+			h.t.Fatalf("processtest: process %s not found", path)
+		}
+		p = child
+	}
+	return p
+}
+
+func (h *Harness) observe(p process.Processor, from, to process.State) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.transitions = append(h.transitions, Transition{
+		Path: process.Path(p), From: from, To: to, Time: h.Clock.Now(),
+	})
+	h.cond.Broadcast()
+}
+
+func (h *Harness) snapshot(path string) *process.Snapshot {
+	s, _ := h.root.Snapshot().Find(path)
+	return s
+}
+
+// Wait
+// block until condition met, condition is checked with lock
+// held each time any transition recorded.
+func (h *Harness) wait(desc string, condition func() bool) {
+	h.t.Helper()
+
+	expired := false
+	timer := time.AfterFunc(h.Timeout, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		expired = true
+		h.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for !condition() {
+		if expired {
+			list := make([]string, 0, len(h.transitions))
+			for _, x := range h.transitions {
+				list = append(list, fmt.Sprintf("    %s: %s -> %s", x.Path, x.From, x.To))
+			}
+			h.t.Fatalf("processtest: timeout waiting %s, transitions:\n%s", desc, strings.Join(list, "\n"))
+		}
+		h.cond.Wait()
+	}
+}
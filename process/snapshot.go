@@ -0,0 +1,124 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	PathSeparator = "/"
+)
+
+type (
+	// Snapshot
+	// state of process and subprocesses at the moment.
+	//
+	// Processes are never restarted automatically and have no
+	// dependencies besides their parent, so Propagate is the
+	// restart policy of process and Children are the
+	// dependents of it.
+	Snapshot struct {
+		Name              string      `json:"name"`
+		Path              string      `json:"path"`
+		State             State       `json:"state"`
+		StartedAt         time.Time   `json:"started_at"`
+		Restarts          int         `json:"restarts"`
+		Propagate         Propagation `json:"propagate"`
+		UnbindWhenStopped bool        `json:"unbind_when_stopped"`
+		Tags              []string    `json:"tags,omitempty"`
+		Children          []*Snapshot `json:"children,omitempty"`
+	}
+)
+
+// Path
+// return process path joined by names from root process.
+//
+//   return "root/consumer/worker"
+func Path(p Processor) string {
+	names := []string{p.Name()}
+	for parent := p.GetParent(); parent != nil; parent = parent.GetParent() {
+		names = append([]string{parent.Name()}, names...)
+	}
+	return strings.Join(names, PathSeparator)
+}
+
+// Find
+// return snapshot of specified path.
+//
+//   s.Find("root/consumer")
+func (o *Snapshot) Find(path string) (snapshot *Snapshot, exists bool) {
+	o.Walk(func(s *Snapshot) bool {
+		if s.Path == path {
+			snapshot, exists = s, true
+		}
+		return !exists
+	})
+	return
+}
+
+// HasTag
+// return true if process tagged with tag.
+func (o *Snapshot) HasTag(tag string) bool {
+	for _, t := range o.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Walk
+// call fn on snapshot and subprocess snapshots in depth first
+// order, stop walking if false returned.
+func (o *Snapshot) Walk(fn func(s *Snapshot) bool) {
+	o.walk(fn)
+}
+
+func (o *Snapshot) walk(fn func(s *Snapshot) bool) bool {
+	if !fn(o) {
+		return false
+	}
+	for _, c := range o.Children {
+		if !c.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// /////////////////////////////////////////////////////////////
+// Processor snapshot.
+// /////////////////////////////////////////////////////////////
+
+func (o *processor) snapshot() *Snapshot {
+	o.mu.RLock()
+	s := &Snapshot{
+		Name:              o.name,
+		State:             o.state,
+		StartedAt:         o.startedAt,
+		Restarts:          o.restarts,
+		Propagate:         o.propagate,
+		UnbindWhenStopped: o.unbindWhenStop,
+	}
+	children := make([]Processor, 0, len(o.subprocesses))
+	for _, child := range o.subprocesses {
+		children = append(children, child)
+	}
+	o.mu.RUnlock()
+
+	s.Path = Path(o)
+	if tags := o.getTags(); len(tags) > 0 {
+		s.Tags = tags
+	}
+
+	// Sort subprocesses by name for stable output.
+	sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+	for _, child := range children {
+		s.Children = append(s.Children, child.Snapshot())
+	}
+	return s
+}
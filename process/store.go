@@ -0,0 +1,214 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	// Permission of persist file.
+	storeFileMode os.FileMode = 0644
+)
+
+var (
+	storeKey = &storeContextKey{}
+)
+
+type (
+	// Store
+	// keyed state of process, it survives restarts.
+	//
+	// Values are kept as json, so they survive snapshot and
+	// restore with the same type. Store of process configured
+	// by Persist is saved into file on each Set and Del, so
+	// values survive crash.
+	Store struct {
+		mu     sync.RWMutex
+		path   string
+		saving sync.Mutex
+		values map[string]json.RawMessage
+	}
+
+	storeContextKey struct{}
+)
+
+// NewStore
+// create and return empty store.
+func NewStore() *Store {
+	return &Store{values: make(map[string]json.RawMessage)}
+}
+
+// StoreFrom
+// return store of process which is calling events with ctx,
+// return nil if ctx not built by process.
+//
+//   func consume(ctx context.Context) (ignored bool) {
+//       var offset int64
+//       store := process.StoreFrom(ctx)
+//       _, _ = store.Get("offset", &offset)
+//       ...
+//       _ = store.Set("offset", offset)
+//   }
+func StoreFrom(ctx context.Context) *Store {
+	if ctx == nil {
+		return nil
+	}
+	if s, ok := ctx.Value(storeKey).(*Store); ok {
+		return s
+	}
+	return nil
+}
+
+// Del
+// delete values of keys, return error if store can not be
+// saved into persist file.
+func (o *Store) Del(keys ...string) error {
+	o.mu.Lock()
+	for _, key := range keys {
+		delete(o.values, key)
+	}
+	path := o.path
+	o.mu.Unlock()
+	return o.persist(path)
+}
+
+// Get
+// unmarshal value of key into v, return false if key not
+// exists.
+func (o *Store) Get(key string, v interface{}) (exists bool, err error) {
+	o.mu.RLock()
+	buf, exists := o.values[key]
+	o.mu.RUnlock()
+
+	if exists {
+		err = json.Unmarshal(buf, v)
+	}
+	return
+}
+
+// Keys
+// return sorted keys of store.
+func (o *Store) Keys() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	keys := make([]string, 0, len(o.values))
+	for key := range o.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Load
+// restore values from json file, values not in file are kept.
+//
+// Return nil if file not exists.
+func (o *Store) Load(path string) error {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	values := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(buf, &values); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key, value := range values {
+		o.values[key] = value
+	}
+	return nil
+}
+
+// Save
+// snapshot values into json file.
+//
+// Values are written to a temporary file and synced to disk
+// then renamed, the file is always complete even if process
+// killed or system crashed.
+func (o *Store) Save(path string) error {
+	o.saving.Lock()
+	defer o.saving.Unlock()
+
+	o.mu.RLock()
+	buf, err := json.Marshal(o.values)
+	o.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	// Content is flushed to disk before renamed, so file is
+	// never empty after crash.
+	if _, err = tmp.Write(buf); err == nil {
+		if err = tmp.Chmod(storeFileMode); err == nil {
+			err = tmp.Sync()
+		}
+	}
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Set
+// marshal v as value of key, return error if store can not be
+// saved into persist file.
+func (o *Store) Set(key string, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	o.values[key] = buf
+	path := o.path
+	o.mu.Unlock()
+	return o.persist(path)
+}
+
+// Bind
+// persist file of store, store is saved into file on each change
+// if path is not empty.
+func (o *Store) bind(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.path = path
+}
+
+func (o *Store) persist(path string) error {
+	if path == "" {
+		return nil
+	}
+	return o.Save(path)
+}
+
+func withStore(ctx context.Context, s *Store) context.Context {
+	if ctx == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, storeKey, s)
+}
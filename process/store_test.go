@@ -0,0 +1,150 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
+)
+
+func TestProcessor_Store(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "consumer.json")
+
+	// Count runs in store, runs is local of each binary.
+	consume := func(runs *int) process.Event {
+		return func(ctx context.Context) (ignored bool) {
+			store := process.StoreFrom(ctx)
+			if _, err := store.Get("runs", runs); err != nil {
+				t.Errorf("get runs: %v", err)
+			}
+			*runs++
+			if err := store.Set("runs", *runs); err != nil {
+				t.Errorf("set runs: %v", err)
+			}
+			<-ctx.Done()
+			return
+		}
+	}
+
+	// Survive restarts.
+	runs := 0
+	p := process.New("consumer").Persist(path).Callback(consume(&runs))
+	h := processtest.New(t, p)
+	h.Start()
+	h.Restart("consumer")
+	h.Restart("consumer")
+	if err = h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 3 {
+		t.Fatalf("expect 3 runs, got %d", runs)
+	}
+
+	// Survive binary restarts, counter of new binary starts
+	// from zero and can only reach 4 by value in file.
+	restarted := 0
+	p = process.New("consumer").Persist(path).Callback(consume(&restarted))
+	h = processtest.New(t, p)
+	h.Start()
+	if err = h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if restarted != 4 {
+		t.Fatalf("expect 4 runs, got %d", restarted)
+	}
+
+	n := 0
+	if exists, err := p.Store().Get("runs", &n); !exists || err != nil || n != 4 {
+		t.Fatalf("unexpected store value: %d, %v, %v", n, exists, err)
+	}
+}
+
+func TestProcessor_StoreCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var (
+		path = filepath.Join(dir, "consumer.json")
+		set  = make(chan struct{})
+	)
+
+	p := process.New("consumer").Persist(path).Callback(func(ctx context.Context) (ignored bool) {
+		if err := process.StoreFrom(ctx).Set("offset", 42); err != nil {
+			t.Errorf("set offset: %v", err)
+		}
+		close(set)
+		<-ctx.Done()
+		return
+	})
+	h := processtest.New(t, p)
+	h.Start()
+	<-set
+
+	// Read file while process still running, as if it crashed.
+	offset, store := 0, process.NewStore()
+	if err = store.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := store.Get("offset", &offset); !exists || err != nil || offset != 42 {
+		t.Fatalf("unexpected saved offset: %d, %v, %v", offset, exists, err)
+	}
+
+	if err = h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStore_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "store.json")
+	s := process.NewStore()
+	if err = s.Set("runs", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Readable by others as file written by ioutil.WriteFile,
+	// and temporary file is removed.
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0644 {
+		t.Errorf("unexpected mode: %v", info.Mode())
+	}
+	if list, _ := ioutil.ReadDir(dir); len(list) != 1 {
+		t.Errorf("unexpected files: %d", len(list))
+	}
+
+	loaded, n := process.NewStore(), 0
+	if err = loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := loaded.Get("runs", &n); !exists || err != nil || n != 3 {
+		t.Errorf("unexpected store value: %d, %v, %v", n, exists, err)
+	}
+}
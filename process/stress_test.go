@@ -0,0 +1,100 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
+)
+
+// TestProcessor_Stress
+// run concurrent operations on running process tree, it is
+// designed to be run with race detector.
+//
+//   go test -race -run Stress ./process/
+func TestProcessor_Stress(t *testing.T) {
+	var (
+		block = func(ctx context.Context) (ignored bool) {
+			<-ctx.Done()
+			return
+		}
+		noop = func(ctx context.Context) (ignored bool) { return }
+
+		children = 8
+		loops    = 300
+		workers  = 8
+	)
+
+	if testing.Short() {
+		loops = 50
+	}
+
+	root := process.New("root").Callback(block)
+	for i := 0; i < children; i++ {
+		root.Add(process.New(fmt.Sprintf("c%d", i)).Before(noop).Callback(block).After(noop))
+	}
+
+	h := processtest.New(t, root)
+	h.Start()
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+
+			r := rand.New(rand.NewSource(seed))
+			for i := 0; i < loops; i++ {
+				name := fmt.Sprintf("c%d", r.Intn(children))
+				child, exists := root.Get(name)
+
+				switch r.Intn(10) {
+				case 0:
+					root.Add(process.New(name).Callback(block))
+				case 1:
+					if exists {
+						root.Del(child)
+					}
+				case 2:
+					if exists {
+						child.Restart()
+					}
+				case 3:
+					if exists {
+						child.Stop()
+					}
+				case 4:
+					_ = root.StartChild(name)
+				case 5:
+					if exists {
+						child.Callback(block).Before(noop).After(noop).Panic(nil).UnbindWhenStopped(r.Intn(2) == 0)
+					}
+				case 6:
+					_ = root.Snapshot()
+				case 7:
+					if exists {
+						_, _, _ = child.Healthy(), child.Stopped(), child.State()
+					}
+				case 8:
+					if r.Intn(20) == 0 {
+						root.Restart()
+					}
+				default:
+					root.Propagate(process.PropagateNone)
+				}
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,82 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"sort"
+)
+
+// /////////////////////////////////////////////////////////////
+// Processor tag methods.
+// /////////////////////////////////////////////////////////////
+
+// Tags
+// return sorted tags of process.
+func (o *processor) getTags() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	tags := make([]string, 0, len(o.tags))
+	for tag := range o.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (o *processor) hasTag(tag string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.tags[tag]
+}
+
+// RestartTagged
+// restart process and subprocesses with tag.
+func (o *processor) restartTagged(tag string) (n int) {
+	if o.hasTag(tag) {
+		o.restart()
+		return 1
+	}
+	for _, child := range o.doChildren() {
+		n += child.RestartTagged(tag)
+	}
+	return
+}
+
+// SnapshotTagged
+// return snapshots of process and subprocesses with tag.
+func (o *processor) snapshotTagged(tag string) []*Snapshot {
+	list := make([]*Snapshot, 0)
+	o.snapshot().Walk(func(s *Snapshot) bool {
+		if s.HasTag(tag) {
+			list = append(list, s)
+		}
+		return true
+	})
+	return list
+}
+
+// StopTagged
+// stop process and subprocesses with tag.
+func (o *processor) stopTagged(tag string) (n int) {
+	if o.hasTag(tag) {
+		o.stop()
+		return 1
+	}
+	for _, child := range o.doChildren() {
+		n += child.StopTagged(tag)
+	}
+	return
+}
+
+// Tag
+// add tags on process.
+func (o *processor) tag(tags []string) Processor {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, tag := range tags {
+		o.tags[tag] = true
+	}
+	return o
+}
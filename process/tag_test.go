@@ -0,0 +1,64 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
+)
+
+func TestProcessor_Tag(t *testing.T) {
+	block := func(ctx context.Context) (ignored bool) {
+		<-ctx.Done()
+		return
+	}
+
+	root := process.New("root").Callback(block).Add(
+		process.New("c1").Callback(block).Tag("io"),
+		process.New("c2").Callback(block).Tag("io", "consumer"),
+		process.New("c3").Callback(block).Add(
+			process.New("c31").Callback(block).Tag("consumer"),
+			process.New("c32").Callback(block),
+		),
+	)
+
+	h := processtest.New(t, root)
+	h.Start()
+	h.ExpectState("root/c3/c31", process.StateRunning)
+	h.ExpectState("root/c3/c32", process.StateRunning)
+
+	// Snapshot.
+	var paths []string
+	for _, s := range root.SnapshotTagged("consumer") {
+		paths = append(paths, s.Path)
+	}
+	if len(paths) != 2 || paths[0] != "root/c2" || paths[1] != "root/c3/c31" {
+		t.Fatalf("unexpected tagged snapshots: %v", paths)
+	}
+
+	// Restart.
+	if n := root.RestartTagged("consumer"); n != 2 {
+		t.Fatalf("expect 2 restarted, got %d", n)
+	}
+	h.Expect("consumers restarted", func(s *process.Snapshot) bool {
+		c2, _ := s.Find("root/c2")
+		c31, _ := s.Find("root/c3/c31")
+		return c2.Restarts == 1 && c2.State == process.StateRunning && c31.Restarts == 1 && c31.State == process.StateRunning
+	})
+
+	// Stop.
+	if n := root.StopTagged("io"); n != 2 {
+		t.Fatalf("expect 2 stopped, got %d", n)
+	}
+	h.ExpectState("root/c1", process.StateStopped)
+	h.ExpectState("root/c2", process.StateStopped)
+	h.ExpectState("root/c3/c31", process.StateRunning)
+
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
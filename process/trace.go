@@ -0,0 +1,197 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	SpanAfter    = "process.after"
+	SpanBefore   = "process.before"
+	SpanCallback = "process.callback"
+	SpanRestart  = "process.restart"
+	SpanRun      = "process.run"
+)
+
+var (
+	spanKey = &spanContextKey{}
+)
+
+type (
+	// Tracer
+	// create spans of process lifetime.
+	//
+	// Span created is child of the span carried by ctx, or root
+	// span of new trace if ctx carries no span.
+	Tracer interface {
+		Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+	}
+
+	// Span
+	// of process lifetime.
+	Span interface {
+		// Context
+		// return identity of span.
+		Context() SpanContext
+
+		// End
+		// finish span with result.
+		End(err error)
+	}
+
+	// SpanContext
+	// identity of span.
+	SpanContext struct {
+		TraceId string `json:"trace_id"`
+		SpanId  string `json:"span_id"`
+	}
+
+	// SpanData
+	// finished span.
+	SpanData struct {
+		TraceId    string            `json:"trace_id"`
+		SpanId     string            `json:"span_id"`
+		ParentId   string            `json:"parent_id,omitempty"`
+		Name       string            `json:"name"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+		Start      time.Time         `json:"start"`
+		End        time.Time         `json:"end"`
+		Error      string            `json:"error,omitempty"`
+	}
+
+	// Recorder
+	// tracer keeps finished spans in memory, it is designed
+	// for tests.
+	Recorder struct {
+		mu    sync.Mutex
+		spans []SpanData
+		*tracer
+	}
+
+	noopSpan struct{}
+
+	span struct {
+		data   SpanData
+		once   sync.Once
+		tracer *tracer
+	}
+
+	spanContextKey struct{}
+
+	tracer struct {
+		export func(data SpanData)
+	}
+)
+
+// NewJSONTracer
+// create and return tracer which write finished spans into w
+// as json lines.
+//
+//   proc.Trace(process.NewJSONTracer(os.Stdout))
+func NewJSONTracer(w io.Writer) Tracer {
+	var (
+		encoder = json.NewEncoder(w)
+		mu      sync.Mutex
+	)
+
+	return &tracer{export: func(data SpanData) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = encoder.Encode(data)
+	}}
+}
+
+// NewRecorder
+// create and return in-memory tracer.
+func NewRecorder() *Recorder {
+	o := &Recorder{}
+	o.tracer = &tracer{export: o.record}
+	return o
+}
+
+// SpanFrom
+// return span context carried by ctx.
+func SpanFrom(ctx context.Context) (sc SpanContext, exists bool) {
+	if ctx != nil {
+		sc, exists = ctx.Value(spanKey).(SpanContext)
+	}
+	return
+}
+
+// /////////////////////////////////////////////////////////////
+// Recorder methods.
+// /////////////////////////////////////////////////////////////
+
+// Reset
+// clear recorded spans.
+func (o *Recorder) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.spans = nil
+}
+
+// Spans
+// return recorded spans in finished order.
+func (o *Recorder) Spans() []SpanData {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]SpanData(nil), o.spans...)
+}
+
+func (o *Recorder) record(data SpanData) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.spans = append(o.spans, data)
+}
+
+// /////////////////////////////////////////////////////////////
+// Tracer methods.
+// /////////////////////////////////////////////////////////////
+
+func (o *tracer) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	s := &span{tracer: o, data: SpanData{
+		SpanId: newSpanId(8), Name: name, Attributes: attributes, Start: time.Now(),
+	}}
+
+	if parent, ok := SpanFrom(ctx); ok {
+		s.data.TraceId, s.data.ParentId = parent.TraceId, parent.SpanId
+	} else {
+		s.data.TraceId = newSpanId(16)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, spanKey, s.Context()), s
+}
+
+func (noopSpan) Context() SpanContext { return SpanContext{} }
+func (noopSpan) End(error)            {}
+
+func (o *span) Context() SpanContext {
+	return SpanContext{TraceId: o.data.TraceId, SpanId: o.data.SpanId}
+}
+
+func (o *span) End(err error) {
+	o.once.Do(func() {
+		o.data.End = time.Now()
+		if err != nil {
+			o.data.Error = err.Error()
+		}
+		o.tracer.export(o.data)
+	})
+}
+
+func newSpanId(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
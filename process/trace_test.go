@@ -0,0 +1,91 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package process_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fuyibing/util/v8/process"
+	"github.com/fuyibing/util/v8/process/processtest"
+)
+
+func TestProcessor_Trace(t *testing.T) {
+	block := func(ctx context.Context) (ignored bool) {
+		if _, ok := process.SpanFrom(ctx); !ok {
+			t.Errorf("span not carried by event context")
+		}
+		<-ctx.Done()
+		return
+	}
+
+	recorder := process.NewRecorder()
+	root := process.New("root").Trace(recorder).Callback(block).Add(
+		process.New("c1").Callback(block),
+	)
+
+	h := processtest.New(t, root)
+	h.Start()
+	h.ExpectState("root/c1", process.StateRunning)
+	h.Restart("root/c1")
+	if err := h.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := make(map[string][]process.SpanData)
+	for _, s := range recorder.Spans() {
+		key := s.Attributes["process"] + " " + s.Name
+		spans[key] = append(spans[key], s)
+	}
+
+	expect := func(key string, n int) []process.SpanData {
+		if len(spans[key]) != n {
+			t.Fatalf("expect %d spans of %s, got %d", n, key, len(spans[key]))
+		}
+		return spans[key]
+	}
+
+	run := expect("root process.run", 1)[0]
+	callback := expect("root process.callback", 1)[0]
+	expect("root process.before", 1)
+	expect("root process.after", 1)
+
+	c1Runs := expect("root/c1 process.run", 1)
+	c1Restarts := expect("root/c1 process.restart", 1)
+	c1Callbacks := expect("root/c1 process.callback", 2)
+
+	// Spans mirror process tree.
+	if callback.ParentId != run.SpanId {
+		t.Fatalf("callback span should be child of run span")
+	}
+	if c1Runs[0].ParentId != callback.SpanId || c1Runs[0].TraceId != run.TraceId {
+		t.Fatalf("subprocess run span should be child of parent callback span")
+	}
+	if c1Restarts[0].ParentId != c1Runs[0].SpanId || c1Callbacks[1].ParentId != c1Restarts[0].SpanId {
+		t.Fatalf("restart span should be child of run span and parent of callback span")
+	}
+}
+
+func TestNewJSONTracer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := process.NewJSONTracer(buf)
+
+	ctx, parent := tracer.Start(context.Background(), "parent", nil)
+	_, child := tracer.Start(ctx, "child", map[string]string{"k": "v"})
+	child.End(nil)
+	parent.End(nil)
+
+	decoder := json.NewDecoder(buf)
+	for _, name := range []string{"child", "parent"} {
+		data := process.SpanData{}
+		if err := decoder.Decode(&data); err != nil {
+			t.Fatal(err)
+		}
+		if data.Name != name || data.TraceId != parent.Context().TraceId {
+			t.Fatalf("unexpected span: %+v", data)
+		}
+	}
+}
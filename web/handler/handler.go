@@ -0,0 +1,298 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+// Package handler
+// adapt typed functions into net/http handlers, request is bound
+// and validated by request.Validator, result is written as json.
+//
+//   type GetUser struct {
+//       Id int64 `path:"id" label:"User id" validate:"required,min=1"`
+//   }
+//
+//   func getUser(ctx context.Context, req *GetUser) (*response.Result, error) {
+//       return response.With.Data(user), nil
+//   }
+//
+//   mux.Handle("/user", handler.Middleware()(handler.New(getUser)))
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+
+	"github.com/fuyibing/util/v8/web/request"
+	"github.com/fuyibing/util/v8/web/response"
+)
+
+const (
+	ContentType = "application/json; charset=utf-8"
+)
+
+var (
+	// ErrPanic
+	// written as error result if handler panics, panic value is
+	// passed to Recover of options and never written to client.
+	ErrPanic = fmt.Errorf("internal server error")
+
+	typeContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeError   = reflect.TypeOf((*error)(nil)).Elem()
+	typeResult  = reflect.TypeOf((*response.Result)(nil))
+)
+
+type (
+	// Option
+	// configure handler and middleware.
+	Option func(o *Options)
+
+	// Options
+	// of handler and middleware.
+	Options struct {
+		// Validator
+		// used to bind and validate request, default is
+		// request.Validate.
+		Validator *request.Validator
+
+		// ValidationCode
+		// errno of result if request can not be bound or
+		// validated, default response.UndefinedCode.
+		ValidationCode int
+
+		// ValidationStatus
+		// http status if request can not be bound or validated,
+		// default 400.
+		ValidationStatus int
+
+		// ErrorCode
+		// errno of result if function returned error, default
+		// response.UndefinedCode.
+		ErrorCode int
+
+		// ErrorStatus
+		// http status if function returned error or panic,
+		// default 500.
+		ErrorStatus int
+
+		// Recover
+		// called with panic value and stack if handler panics,
+		// it is used to log the panic. Default is nil, panic is
+		// discarded after ErrPanic written.
+		Recover func(r *http.Request, v interface{}, stack []byte)
+	}
+
+	// Coder
+	// implemented by error returned from function to specify
+	// errno of result.
+	Coder interface {
+		Code() int
+	}
+
+	// StatusCoder
+	// implemented by error returned from function to specify
+	// http status.
+	StatusCoder interface {
+		StatusCode() int
+	}
+
+	handler struct {
+		fn      reflect.Value
+		options *Options
+		req     reflect.Type
+	}
+)
+
+// New
+// return http handler of function.
+//
+// Function must be func(context.Context, *Req) (*response.Result, error)
+// where Req is struct, it panics if signature not matched.
+//
+// Request is bound by Validator.Bind, failures are written as
+// error result with ValidationCode and ValidationStatus. Error
+// returned by function is written with ErrorCode and ErrorStatus,
+// success result is written with status 200, nil result is
+// written as response.With.Success(). Panic of function is
+// recovered as Middleware does.
+func New(fn interface{}, opts ...Option) http.Handler {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func ||
+		t.NumIn() != 2 || t.In(0) != typeContext ||
+		t.In(1).Kind() != reflect.Ptr || t.In(1).Elem().Kind() != reflect.Struct ||
+		t.NumOut() != 2 || t.Out(0) != typeResult || t.Out(1) != typeError {
+		panic(fmt.Sprintf("handler: invalid function %s, expected func(context.Context, *Req) (*response.Result, error)", t))
+	}
+
+	return &handler{fn: v, options: newOptions(opts), req: t.In(1).Elem()}
+}
+
+// Middleware
+// return middleware which negotiates locale of request by
+// Accept-Language header and recovers panic of next handler.
+//
+// ErrPanic is written as error result with ErrorCode and
+// ErrorStatus, panic value and stack are passed to Recover.
+//
+//   http.ListenAndServe(":8080", handler.Middleware(
+//       handler.WithRecover(func(r *http.Request, v interface{}, stack []byte) {
+//           log.Printf("panic %s %s: %v\n%s", r.Method, r.URL.Path, v, stack)
+//       }),
+//   )(mux))
+func Middleware(opts ...Option) func(next http.Handler) http.Handler {
+	o := newOptions(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer o.recover(w, r)
+
+			if request.LocaleFromContext(r.Context()) == "" {
+				locale := o.Validator.Negotiate(r.Header.Get("Accept-Language"))
+				r = r.WithContext(request.NewLocaleContext(r.Context(), locale))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithError
+// set errno and http status of error returned by function.
+func WithError(code, status int) Option {
+	return func(o *Options) { o.ErrorCode, o.ErrorStatus = code, status }
+}
+
+// WithRecover
+// set function called with panic value and stack.
+func WithRecover(fn func(r *http.Request, v interface{}, stack []byte)) Option {
+	return func(o *Options) { o.Recover = fn }
+}
+
+// WithValidation
+// set errno and http status of bind and validation failures.
+func WithValidation(code, status int) Option {
+	return func(o *Options) { o.ValidationCode, o.ValidationStatus = code, status }
+}
+
+// WithValidator
+// set validator to bind and validate request.
+func WithValidator(v *request.Validator) Option {
+	return func(o *Options) { o.Validator = v }
+}
+
+// Write
+// result as json with http status.
+func Write(w http.ResponseWriter, status int, result *response.Result) {
+	buf, err := json.Marshal(result)
+	if err != nil {
+		status = http.StatusInternalServerError
+		buf, _ = json.Marshal(response.With.Error(err))
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf)
+}
+
+// /////////////////////////////////////////////////////////////
+// Handler methods.
+// /////////////////////////////////////////////////////////////
+
+func (o *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer o.options.recover(w, r)
+
+	req := reflect.New(o.req)
+	if err := o.options.Validator.Bind(r, req.Interface()); err != nil {
+		o.fail(w, err)
+		return
+	}
+
+	out := o.fn.Call([]reflect.Value{reflect.ValueOf(r.Context()), req})
+	if err, _ := out[1].Interface().(error); err != nil {
+		o.error(w, err)
+		return
+	}
+
+	result, _ := out[0].Interface().(*response.Result)
+	if result == nil {
+		result = response.With.Success()
+	}
+	Write(w, http.StatusOK, result)
+}
+
+// Error
+// write error returned by function.
+func (o *handler) error(w http.ResponseWriter, err error) {
+	if _, ok := err.(request.FieldErrors); ok {
+		o.fail(w, err)
+		return
+	}
+
+	code, status := o.options.ErrorCode, o.options.ErrorStatus
+	if c, ok := err.(Coder); ok {
+		code = c.Code()
+	}
+	if c, ok := err.(StatusCoder); ok {
+		status = c.StatusCode()
+	}
+	Write(w, status, response.With.ErrorCode(err, code))
+}
+
+// Fail
+// write bind or validation failure.
+func (o *handler) fail(w http.ResponseWriter, err error) {
+	if errs, ok := err.(request.FieldErrors); ok {
+		Write(w, o.options.ValidationStatus, errs.Result(o.options.ValidationCode))
+		return
+	}
+
+	status := o.options.ValidationStatus
+	switch {
+	case errors.Is(err, request.ErrBodyTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, request.ErrUnsupportedContentType):
+		status = http.StatusUnsupportedMediaType
+	}
+	Write(w, status, response.With.ErrorCode(err, o.options.ValidationCode))
+}
+
+// /////////////////////////////////////////////////////////////
+// Options methods.
+// /////////////////////////////////////////////////////////////
+
+// Recover
+// write ErrPanic if panic recovered, http.ErrAbortHandler is
+// panicked again to abort response as net/http does.
+func (o *Options) recover(w http.ResponseWriter, r *http.Request) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	if o.Recover != nil {
+		o.Recover(r, v, debug.Stack())
+	}
+	Write(w, o.ErrorStatus, response.With.ErrorCode(ErrPanic, o.ErrorCode))
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+func newOptions(opts []Option) *Options {
+	o := &Options{
+		Validator:        request.Validate,
+		ValidationCode:   response.UndefinedCode,
+		ValidationStatus: http.StatusBadRequest,
+		ErrorCode:        response.UndefinedCode,
+		ErrorStatus:      http.StatusInternalServerError,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
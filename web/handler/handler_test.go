@@ -0,0 +1,175 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/handler"
+	"github.com/fuyibing/util/v8/web/request"
+	"github.com/fuyibing/util/v8/web/response"
+)
+
+type (
+	createUser struct {
+		Token string `header:"X-Token" label:"Token" validate:"required"`
+		Name  string `json:"name" label:"Name" validate:"required,max=8"`
+	}
+
+	codeError struct{}
+)
+
+func (codeError) Code() int       { return 1001 }
+func (codeError) Error() string   { return "user exists" }
+func (codeError) StatusCode() int { return http.StatusConflict }
+
+func create(_ context.Context, req *createUser) (*response.Result, error) {
+	switch req.Name {
+	case "exists":
+		return nil, codeError{}
+	case "boom":
+		panic("boom")
+	case "empty":
+		return nil, nil
+	}
+	return response.With.Data(map[string]string{"name": req.Name}), nil
+}
+
+func TestNew(t *testing.T) {
+	h := handler.Middleware()(handler.New(create, handler.WithValidation(2, http.StatusUnprocessableEntity)))
+
+	for _, c := range []struct {
+		body   string
+		token  string
+		status int
+		errno  int
+		result string
+	}{
+		{`{"name":"alice"}`, "t", http.StatusOK, 0, `{"name":"alice"}`},
+		{`{"name":"empty"}`, "t", http.StatusOK, 0, `{}`},
+		{`{"name":""}`, "t", http.StatusUnprocessableEntity, 2, ""},
+		{`{"name":"alice"}`, "", http.StatusUnprocessableEntity, 2, ""},
+		{`{"name":`, "t", http.StatusUnprocessableEntity, 2, ""},
+		{`{"name":"exists"}`, "t", http.StatusConflict, 1001, ""},
+		{`{"name":"boom"}`, "t", http.StatusInternalServerError, response.UndefinedCode, ""},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(c.body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept-Language", "en")
+		if c.token != "" {
+			r.Header.Set("X-Token", c.token)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		result := &struct {
+			Data  json.RawMessage `json:"data"`
+			Errno int             `json:"errno"`
+			Error string          `json:"error"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+			t.Fatalf("%s: invalid response: %s", c.body, w.Body.String())
+		}
+		if w.Code != c.status || result.Errno != c.errno {
+			t.Errorf("%s: unexpected response: %d %s", c.body, w.Code, w.Body.String())
+		}
+		if c.result != "" && string(result.Data) != c.result {
+			t.Errorf("%s: unexpected data: %s", c.body, result.Data)
+		}
+	}
+}
+
+func TestNew_FieldErrors(t *testing.T) {
+	h := handler.Middleware()(handler.New(create))
+
+	r := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(`{"name":"too long name"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	result := &struct {
+		Data struct {
+			Fields request.FieldErrors `json:"fields"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+		t.Fatalf("invalid response: %s", w.Body.String())
+	}
+
+	fields := make([]string, 0)
+	for _, e := range result.Data.Fields {
+		fields = append(fields, fmt.Sprintf("%s:%s", e.Field, e.Tag))
+	}
+	if w.Code != http.StatusBadRequest || strings.Join(fields, ",") != "X-Token:required,name:max" {
+		t.Errorf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic of invalid function")
+		}
+	}()
+	handler.New(func(ctx context.Context, req createUser) error { return nil })
+}
+
+func TestNew_Panic(t *testing.T) {
+	var (
+		recovered interface{}
+		stack     string
+	)
+	hook := handler.WithRecover(func(_ *http.Request, v interface{}, s []byte) {
+		recovered, stack = v, string(s)
+	})
+
+	for name, h := range map[string]http.Handler{
+		"handler": handler.New(create, hook),
+		"middleware": handler.Middleware(hook)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var m map[string]int
+			m["boom"]++
+		})),
+	} {
+		recovered, stack = nil, ""
+
+		r := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(`{"name":"boom"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Token", "t")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		// Panic value is not written to client.
+		result := &struct {
+			Error string `json:"error"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+			t.Fatalf("%s: invalid response: %s", name, w.Body.String())
+		}
+		if w.Code != http.StatusInternalServerError || result.Error != handler.ErrPanic.Error() {
+			t.Errorf("%s: unexpected response: %d %s", name, w.Code, w.Body.String())
+		}
+		if recovered == nil || !strings.Contains(stack, "handler_test.go") {
+			t.Errorf("%s: unexpected recovered: %v\n%s", name, recovered, stack)
+		}
+	}
+
+	// Aborted response is panicked again.
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected ErrAbortHandler, got %v", v)
+		}
+	}()
+	handler.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
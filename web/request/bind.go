@@ -0,0 +1,345 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultMaxMemory
+	// used to parse multipart form.
+	DefaultMaxMemory = 32 << 20
+
+	// TagType
+	// tag of field error if value can not be converted into
+	// field type.
+	TagType = "type"
+)
+
+var (
+	// PathValue
+	// return value of path parameter, default read parameters
+	// carried by NewPathContext. Override it to adapt router.
+	//
+	//   request.PathValue = func(r *http.Request, name string) string {
+	//       return chi.URLParam(r, name)
+	//   }
+	PathValue = func(r *http.Request, name string) string {
+		if params, ok := r.Context().Value(pathKey).(map[string]string); ok {
+			return params[name]
+		}
+		return ""
+	}
+
+	// Messages of conversion failures, replace by Translate
+	// with TagType.
+	bindMessages = map[string]string{
+		LocaleEN:     "{0} must be a valid {1}",
+		LocaleZH:     "{0}必须是有效的{1}",
+		LocaleZHHant: "{0}必須是有效的{1}",
+	}
+
+	// Tags of request sources, in order of filling.
+	bindSources = []string{"path", "query", "form", "header", "cookie"}
+
+	pathKey = &pathContextKey{}
+)
+
+type (
+	pathContextKey struct{}
+
+	binder struct {
+		errs      FieldErrors
+		locale    string
+		r         *http.Request
+		validator *Validator
+	}
+)
+
+// Bind
+// fill struct from request and return validate result of
+// default validator.
+func Bind(r *http.Request, v interface{}) error { return Validate.Bind(r, v) }
+
+// NewPathContext
+// return context carries path parameters.
+//
+//   r = r.WithContext(request.NewPathContext(r.Context(), map[string]string{"id": "1"}))
+func NewPathContext(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, pathKey, params)
+}
+
+// Bind
+// fill struct from request and return validate result
+// translated in locale negotiated by Accept-Language header.
+//
+// Fields are filled by tags in order of json (body), path,
+// query, form, header and cookie. Nested struct without tags
+// is filled recursively.
+//
+//   type Req struct {
+//       Id      int64     `path:"id" validate:"required"`
+//       Page    int       `query:"page" validate:"min=1"`
+//       Ids     []int     `query:"ids"`
+//       Since   time.Time `query:"since" layout:"2006-01-02"`
+//       Token   *string   `header:"X-Token"`
+//       Session string    `cookie:"session"`
+//       Name    string    `json:"name" label:"Name"`
+//   }
+//
+// Body is decoded by decoder registered for Content-Type, form
+// and multipart body fill fields with form tag.
+//
+// Return DecodeError if body can not be decoded, or FieldErrors
+// if conversion or validation failed.
+func (o *Validator) Bind(r *http.Request, v interface{}) error {
+	locale := LocaleFromContext(r.Context())
+	if locale == "" {
+		locale = o.Negotiate(r.Header.Get("Accept-Language"))
+	}
+
+	if err := o.bindBody(r, v); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return o.StructLocale(v, locale)
+	}
+
+	b := &binder{errs: make(FieldErrors, 0), locale: locale, r: r, validator: o}
+	if err := b.parseForm(); err != nil {
+		return err
+	}
+	b.fill(rv.Elem(), rv.Elem().Type().Name())
+	if len(b.errs) > 0 {
+		return b.errs
+	}
+	return o.StructLocale(v, locale)
+}
+
+// Bind body
+// decode body by decoder of Content-Type, form and multipart
+// body are parsed by binder.
+//
+// Body is streamed into decoder, so size limitation of decoder
+// applies before body read into memory.
+func (o *Validator) bindBody(r *http.Request, v interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	ct := r.Header.Get("Content-Type")
+	switch mediaType(ct) {
+	case ContentTypeForm, ContentTypeMultipart:
+		return nil
+	}
+
+	// Skip leading spaces, body with spaces only is empty.
+	body := bufio.NewReader(r.Body)
+	for {
+		c, err := body.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !unicode.IsSpace(rune(c)) {
+			_ = body.UnreadByte()
+			break
+		}
+	}
+	return o.decode(v, ct, body)
+}
+
+// /////////////////////////////////////////////////////////////
+// Binder methods.
+// /////////////////////////////////////////////////////////////
+
+func (o *binder) fill(rv reflect.Value, namespace string) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if skipField(f) {
+			continue
+		}
+
+		fv := rv.Field(i)
+		ns := namespace + "." + f.Name
+
+		source, key := o.source(f)
+		if source == "" {
+			// Nested struct without tags.
+			if ft := indirectType(f.Type); ft.Kind() == reflect.Struct && ft != typeTime {
+				if f.Anonymous {
+					ns = namespace
+				}
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						if !o.has(ft) {
+							continue
+						}
+						fv.Set(reflect.New(ft))
+					}
+					fv = fv.Elem()
+				}
+				o.fill(fv, ns)
+			}
+			continue
+		}
+
+		// Uploaded files.
+		if source == "form" && (f.Type == typeFileHeader || f.Type == reflect.SliceOf(typeFileHeader)) {
+			if o.r.MultipartForm != nil {
+				if list := o.r.MultipartForm.File[key]; len(list) > 0 {
+					if f.Type == typeFileHeader {
+						fv.Set(reflect.ValueOf(list[0]))
+					} else {
+						fv.Set(reflect.ValueOf(list))
+					}
+				}
+			}
+			continue
+		}
+
+		values := o.values(source, key)
+		if len(values) == 0 {
+			continue
+		}
+		if err := convertValue(fv, values, f.Tag.Get("layout")); err != nil {
+			o.errs = append(o.errs, o.fieldError(f, ns, key, values))
+		}
+	}
+}
+
+func (o *binder) fieldError(f reflect.StructField, namespace, key string, values []string) *FieldError {
+	label := f.Tag.Get("label")
+	if label == "" {
+		label = f.Name
+	}
+
+	message, _ := o.validator.message(TagType, o.locale)
+
+	var value interface{} = values[0]
+	if len(values) > 1 {
+		value = values
+	}
+
+	param := typeName(f.Type)
+	return &FieldError{
+		Namespace: namespace,
+		Field:     key,
+		Label:     label,
+		Tag:       TagType,
+		Param:     param,
+		Value:     value,
+		Message:   formatMessage(message, label, param, value, ""),
+	}
+}
+
+// Has
+// return true if any field of struct type has value in request.
+func (o *binder) has(rt reflect.Type) bool {
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if skipField(f) {
+			continue
+		}
+		if source, key := o.source(f); source != "" {
+			if len(o.values(source, key)) > 0 {
+				return true
+			}
+			continue
+		}
+		if ft := indirectType(f.Type); ft.Kind() == reflect.Struct && ft != typeTime && o.has(ft) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *binder) parseForm() error {
+	ct, _, _ := mime.ParseMediaType(o.r.Header.Get("Content-Type"))
+	switch ct {
+	case ContentTypeMultipart:
+		if err := o.r.ParseMultipartForm(DefaultMaxMemory); err != nil {
+			return &DecodeError{Kind: ErrInvalidForm, ContentType: ct, Err: err}
+		}
+	case ContentTypeForm:
+		if err := o.r.ParseForm(); err != nil {
+			return &DecodeError{Kind: ErrInvalidForm, ContentType: ct, Err: err}
+		}
+	}
+	return nil
+}
+
+// Source
+// return first request source tag and key of field.
+func (o *binder) source(f reflect.StructField) (source, key string) {
+	for _, s := range bindSources {
+		if tag, ok := f.Tag.Lookup(s); ok {
+			if key = strings.Split(tag, ",")[0]; key == "-" {
+				return "", ""
+			}
+			if key == "" {
+				key = f.Name
+			}
+			return s, key
+		}
+	}
+	return "", ""
+}
+
+func (o *binder) values(source, key string) []string {
+	switch source {
+	case "path":
+		if s := PathValue(o.r, key); s != "" {
+			return []string{s}
+		}
+	case "query":
+		return o.r.URL.Query()[key]
+	case "form":
+		if o.r.PostForm != nil {
+			return o.r.PostForm[key]
+		}
+	case "header":
+		return o.r.Header[http.CanonicalHeaderKey(key)]
+	case "cookie":
+		if c, err := o.r.Cookie(key); err == nil {
+			return []string{c.Value}
+		}
+	}
+	return nil
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Skip field
+// return true if field can not be filled, unexported fields are
+// skipped except embedded structs, embedded pointers of
+// unexported types are skipped as encoding/json does.
+func skipField(f reflect.StructField) bool {
+	if f.PkgPath == "" {
+		return false
+	}
+	return !f.Anonymous || f.Type.Kind() == reflect.Ptr
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,142 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	bindPaging struct {
+		Page  int `query:"page"`
+		Limit int `query:"limit"`
+	}
+
+	bindReq struct {
+		*bindPaging
+
+		Id       int64         `path:"id" validate:"required"`
+		Size     uint8         `query:"size"`
+		Score    float64       `query:"score"`
+		Enabled  bool          `query:"enabled"`
+		Since    time.Time     `query:"since" layout:"2006-01-02"`
+		Until    time.Time     `query:"until"`
+		Timeout  time.Duration `query:"timeout"`
+		Ids      []int         `query:"ids"`
+		Tags     []string      `query:"tag"`
+		Token    *string       `header:"X-Token"`
+		Limit    *int          `query:"max"`
+		Ip       net.IP        `query:"ip"`
+		Session  string        `cookie:"session"`
+		Ignored  string        `query:"-"`
+		internal string
+		Filter   struct {
+			Status int `query:"status"`
+		}
+		Owner *struct {
+			Name string `query:"owner"`
+		}
+	}
+)
+
+func TestBind(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/user/7?page=2&size=8&score=1.5&enabled=true&since=2023-03-01"+
+		"&until=1677628800&timeout=1m30s&ids=1,2,3&tag=a&tag=b&max=20&ip=127.0.0.1&Ignored=x&status=1", nil)
+	r.Header.Set("X-Token", "token")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+	r = r.WithContext(request.NewPathContext(r.Context(), map[string]string{"id": "7"}))
+
+	req := &bindReq{}
+	if err := request.Bind(r, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, c := range []struct {
+		name            string
+		value, expected interface{}
+	}{
+		{"id", req.Id, int64(7)},
+		{"size", req.Size, uint8(8)},
+		{"score", req.Score, 1.5},
+		{"enabled", req.Enabled, true},
+		{"since", req.Since, time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"until", req.Until.Unix(), int64(1677628800)},
+		{"timeout", req.Timeout, time.Second * 90},
+		{"ids", req.Ids, []int{1, 2, 3}},
+		{"tags", req.Tags, []string{"a", "b"}},
+		{"token", *req.Token, "token"},
+		{"max", *req.Limit, 20},
+		{"ip", req.Ip.String(), "127.0.0.1"},
+		{"session", req.Session, "s1"},
+		{"ignored", req.Ignored, ""},
+		{"status", req.Filter.Status, 1},
+	} {
+		if !reflect.DeepEqual(c.value, c.expected) {
+			t.Errorf("%s: got %v, expected %v", c.name, c.value, c.expected)
+		}
+	}
+
+	// Embedded pointer of unexported type is skipped, nested
+	// pointer is allocated only if any value given.
+	if req.bindPaging != nil || req.Owner != nil {
+		t.Errorf("unexpected allocated struct: %v, %v", req.bindPaging, req.Owner)
+	}
+}
+
+func TestBind_Conversion(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/user?size=300&enabled=yes&ids=1,x&since=2023/03/01&owner=alice", nil)
+	r.Header.Set("Accept-Language", "en")
+	r = r.WithContext(request.NewPathContext(r.Context(), map[string]string{"id": "abc"}))
+
+	req := &bindReq{}
+	errs, ok := request.Bind(r, req).(request.FieldErrors)
+	if !ok {
+		t.Fatalf("expected field errors")
+	}
+
+	expected := []struct {
+		namespace, field, param string
+		value                   interface{}
+	}{
+		{"bindReq.Id", "id", "int64", "abc"},
+		{"bindReq.Size", "size", "uint8", "300"},
+		{"bindReq.Enabled", "enabled", "bool", "yes"},
+		{"bindReq.Since", "since", "time", "2023/03/01"},
+		{"bindReq.Ids", "ids", "[]int", "1,x"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for i, c := range expected {
+		e := errs[i]
+		if e.Tag != request.TagType || e.Namespace != c.namespace || e.Field != c.field || e.Param != c.param || e.Value != c.value {
+			t.Errorf("%d: unexpected failure: %+v", i, e)
+		}
+	}
+	if s := errs[0].Message; s != "Id must be a valid int64" {
+		t.Errorf("unexpected message: %s", s)
+	}
+
+	// Valid values are still filled.
+	if req.Owner == nil || req.Owner.Name != "alice" {
+		t.Errorf("unexpected owner: %v", req.Owner)
+	}
+}
+
+func TestBind_Validation(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/user", nil)
+	r = r.WithContext(request.NewLocaleContext(r.Context(), request.LocaleEN))
+
+	errs, ok := request.Bind(r, &bindReq{}).(request.FieldErrors)
+	if !ok || len(errs) != 1 || errs[0].Field != "id" || errs[0].Tag != "required" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
@@ -0,0 +1,246 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+// Package cn
+// validation tags for mainland China.
+//
+//   if err := cn.Register(request.Validate); err != nil {
+//       panic(err)
+//   }
+//
+//   type Req struct {
+//       Mobile string `json:"mobile" label:"Mobile" validate:"required,cn_mobile"`
+//       IdCard string `json:"id_card" label:"ID card" validate:"omitempty,cn_idcard"`
+//   }
+package cn
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	i18nValidator "github.com/go-playground/validator/v10"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+const (
+	TagBankCard = "cn_bankcard"
+	TagIdCard   = "cn_idcard"
+	TagMobile   = "cn_mobile"
+	TagName     = "cn_name"
+	TagPlate    = "cn_plate"
+	TagPostcode = "cn_postcode"
+	TagUscc     = "cn_uscc"
+)
+
+var (
+	// Rules
+	// registered by Register, keyed by tag.
+	Rules = map[string]Rule{
+		TagBankCard: {
+			Check: IsBankCard,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid bank card number",
+				request.LocaleZH:     "{0}必须是有效的银行卡号",
+				request.LocaleZHHant: "{0}必須是有效的銀行卡號",
+			},
+		},
+		TagIdCard: {
+			Check: IsIdCard,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid resident identity card number",
+				request.LocaleZH:     "{0}必须是有效的身份证号码",
+				request.LocaleZHHant: "{0}必須是有效的身分證號碼",
+			},
+		},
+		TagMobile: {
+			Check: IsMobile,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid mobile number",
+				request.LocaleZH:     "{0}必须是有效的手机号码",
+				request.LocaleZHHant: "{0}必須是有效的手機號碼",
+			},
+		},
+		TagName: {
+			Check: IsName,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid Chinese name",
+				request.LocaleZH:     "{0}必须是有效的中文姓名",
+				request.LocaleZHHant: "{0}必須是有效的中文姓名",
+			},
+		},
+		TagPlate: {
+			Check: IsPlate,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid license plate number",
+				request.LocaleZH:     "{0}必须是有效的车牌号码",
+				request.LocaleZHHant: "{0}必須是有效的車牌號碼",
+			},
+		},
+		TagPostcode: {
+			Check: IsPostcode,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid postal code",
+				request.LocaleZH:     "{0}必须是有效的邮政编码",
+				request.LocaleZHHant: "{0}必須是有效的郵遞區號",
+			},
+		},
+		TagUscc: {
+			Check: IsUscc,
+			Messages: map[string]string{
+				request.LocaleEN:     "{0} must be a valid unified social credit code",
+				request.LocaleZH:     "{0}必须是有效的统一社会信用代码",
+				request.LocaleZHHant: "{0}必須是有效的統一社會信用代碼",
+			},
+		},
+	}
+
+	regexMobile   = regexp.MustCompile(`^(?:\+?86)?1[3-9]\d{9}$`)
+	regexPlate    = regexp.MustCompile(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼][A-HJ-NP-Z](?:[A-HJ-NP-Z0-9]{4}[A-HJ-NP-Z0-9挂学警]|[DF][A-HJ-NP-Z0-9]\d{4}|\d{5}[DF])$`)
+	regexPostcode = regexp.MustCompile(`^\d{6}$`)
+
+	idCardChecksum = "10X98765432"
+	idCardWeights  = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+	usccCharset = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+	usccWeights = []int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+)
+
+type (
+	// Rule
+	// check function and messages of locales.
+	Rule struct {
+		Check    func(s string) bool
+		Messages map[string]string
+	}
+)
+
+// Register
+// all tags on validator with messages of locales, locales
+// without message use english.
+//
+// It is also an option of request.NewValidator.
+//
+//   v, err := request.NewValidator(cn.Register)
+func Register(v *request.Validator) error {
+	for tag, rule := range Rules {
+		check := rule.Check
+		if err := v.RegisterWithMessages(tag, rule.Messages, func(f i18nValidator.FieldLevel) bool {
+			return check(f.Field().String())
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsBankCard
+// return true if s is 12 to 19 digits and passes Luhn check.
+func IsBankCard(s string) bool {
+	if n := len(s); n < 12 || n > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if (len(s)-1-i)%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// IsIdCard
+// return true if s is 18-digit resident identity card number
+// with valid birth date and checksum.
+func IsIdCard(s string) bool {
+	if len(s) != 18 || s[0] == '0' {
+		return false
+	}
+
+	sum := 0
+	for i, w := range idCardWeights {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		sum += int(c-'0') * w
+	}
+
+	if birth, err := time.Parse("20060102", s[6:14]); err != nil || birth.Year() < 1900 || birth.After(time.Now()) {
+		return false
+	}
+	return strings.ToUpper(s[17:]) == idCardChecksum[sum%11:sum%11+1]
+}
+
+// IsMobile
+// return true if s is mainland mobile number, +86 or 86 prefix
+// is allowed.
+func IsMobile(s string) bool { return regexMobile.MatchString(s) }
+
+// IsName
+// return true if s is 2 to 20 Chinese characters, middle dot
+// is allowed between parts of minority names.
+//
+//   "张三", "阿依古丽·买买提"
+func IsName(s string) bool {
+	if n := utf8.RuneCountInString(s); n < 2 || n > 20 {
+		return false
+	}
+
+	for _, part := range strings.Split(s, "·") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if !unicode.Is(unicode.Han, r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsPlate
+// return true if s is license plate number, include new energy
+// plates.
+//
+//   "京A12345", "粤BD12345", "沪A12345D"
+func IsPlate(s string) bool { return regexPlate.MatchString(s) }
+
+// IsPostcode
+// return true if s is 6-digit postal code.
+func IsPostcode(s string) bool { return regexPostcode.MatchString(s) }
+
+// IsUscc
+// return true if s is 18-char unified social credit code with
+// valid checksum.
+func IsUscc(s string) bool {
+	if len(s) != 18 {
+		return false
+	}
+
+	s = strings.ToUpper(s)
+	sum := 0
+	for i, w := range usccWeights {
+		n := strings.IndexByte(usccCharset, s[i])
+		if n < 0 {
+			return false
+		}
+		sum += n * w
+	}
+
+	check := (31 - sum%31) % 31
+	return s[17] == usccCharset[check]
+}
@@ -0,0 +1,103 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package cn_test
+
+import (
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+	"github.com/fuyibing/util/v8/web/request/cn"
+)
+
+func TestRules(t *testing.T) {
+	for _, c := range []struct {
+		tag   string
+		value string
+		valid bool
+	}{
+		{cn.TagMobile, "13800138000", true},
+		{cn.TagMobile, "+8619912345678", true},
+		{cn.TagMobile, "12800138000", false},
+		{cn.TagMobile, "1380013800", false},
+
+		{cn.TagIdCard, "11010519491231002X", true},
+		{cn.TagIdCard, "11010519491231002x", true},
+		{cn.TagIdCard, "440304199003071230", false},
+		{cn.TagIdCard, "11010519491331002X", false},
+		{cn.TagIdCard, "1101051949123100", false},
+
+		{cn.TagUscc, "91350100M000100Y43", true},
+		{cn.TagUscc, "91110000600037341L", true},
+		{cn.TagUscc, "91350100M000100Y44", false},
+		{cn.TagUscc, "91350100M000100I43", false},
+
+		{cn.TagPostcode, "100000", true},
+		{cn.TagPostcode, "010000", true},
+		{cn.TagPostcode, "10000", false},
+
+		{cn.TagBankCard, "6222021234567890128", true},
+		{cn.TagBankCard, "4111111111111111", true},
+		{cn.TagBankCard, "6222021234567890127", false},
+		{cn.TagBankCard, "62220212345", false},
+
+		{cn.TagPlate, "京A12345", true},
+		{cn.TagPlate, "粤BD12345", true},
+		{cn.TagPlate, "沪A12345D", true},
+		{cn.TagPlate, "京I12345", false},
+		{cn.TagPlate, "A12345", false},
+
+		{cn.TagName, "张三", true},
+		{cn.TagName, "阿依古丽·买买提", true},
+		{cn.TagName, "张", false},
+		{cn.TagName, "Zhang San", false},
+		{cn.TagName, "张三·", false},
+	} {
+		if valid := cn.Rules[c.tag].Check(c.value); valid != c.valid {
+			t.Errorf("%s(%q): expected %v, got %v", c.tag, c.value, c.valid, valid)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type req struct {
+		Mobile string `json:"mobile" label:"Mobile" validate:"cn_mobile"`
+	}
+
+	v, err := request.NewValidator(cn.Register)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	for _, c := range []struct {
+		locale  string
+		message string
+	}{
+		{request.LocaleEN, "Mobile must be a valid mobile number"},
+		{request.LocaleZH, "Mobile必须是有效的手机号码"},
+		{request.LocaleZHHant, "Mobile必須是有效的手機號碼"},
+	} {
+		err = v.StructLocale(&req{Mobile: "123"}, c.locale)
+		errs, ok := err.(request.FieldErrors)
+		if !ok || len(errs) != 1 {
+			t.Fatalf("%s: unexpected error: %v", c.locale, err)
+		}
+		if errs[0].Tag != cn.TagMobile || errs[0].Message != c.message {
+			t.Errorf("%s: unexpected failure: %s %s", c.locale, errs[0].Tag, errs[0].Message)
+		}
+	}
+
+	if err = v.Struct(&req{Mobile: "13800138000"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Default validator is not changed.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected panic of unregistered tag on default validator")
+			}
+		}()
+		_ = request.Validate.Struct(&req{Mobile: "13800138000"})
+	}()
+}
@@ -0,0 +1,155 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeLayout
+	// used to convert string into time.Time if layout tag not
+	// defined on field.
+	DefaultTimeLayout = time.RFC3339
+)
+
+var (
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	typeTime            = reflect.TypeOf(time.Time{})
+)
+
+// Convert
+// string values into field value.
+//
+// Slice field accepts multiple values, single value is split by
+// comma. Time field is parsed with layout tag of field, or unix
+// seconds if value is integer.
+func convertValue(fv reflect.Value, values []string, layout string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	// Allocate pointer.
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return convertValue(fv.Elem(), values, layout)
+	}
+
+	// Text unmarshaler.
+	if fv.CanAddr() && fv.Addr().Type().Implements(typeTextUnmarshaler) && fv.Type() != typeTime {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	switch fv.Type() {
+	case typeTime:
+		return convertTime(fv, values[0], layout)
+	case typeDuration:
+		d, err := time.ParseDuration(values[0])
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			fv.SetBytes([]byte(values[0]))
+			return nil
+		}
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		list := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := convertValue(list.Index(i), []string{strings.TrimSpace(s)}, layout); err != nil {
+				return err
+			}
+		}
+		fv.Set(list)
+		return nil
+	}
+
+	return convertScalar(fv, values[0])
+}
+
+func convertScalar(fv reflect.Value, s string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+func convertTime(fv reflect.Value, s, layout string) error {
+	if layout == "" {
+		layout = DefaultTimeLayout
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		n, ne := strconv.ParseInt(s, 10, 64)
+		if ne != nil {
+			return err
+		}
+		t = time.Unix(n, 0)
+	}
+	fv.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// Type name
+// return name of field type used in conversion messages.
+//
+//   int, []int, time, duration
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case typeTime:
+		return "time"
+	case typeDuration:
+		return "duration"
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return "[]" + typeName(t.Elem())
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,425 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const (
+	ContentTypeForm      = "application/x-www-form-urlencoded"
+	ContentTypeJson      = "application/json"
+	ContentTypeMultipart = "multipart/form-data"
+	ContentTypeXml       = "application/xml"
+)
+
+var (
+	ErrInvalidForm            = fmt.Errorf("invalid form")
+	ErrInvalidXml             = fmt.Errorf("invalid xml")
+	ErrUnsupportedContentType = fmt.Errorf("unsupported content type")
+
+	typeFileHeader    = reflect.TypeOf((*multipart.FileHeader)(nil))
+	typeMultipartForm = reflect.TypeOf((*multipart.Form)(nil))
+)
+
+type (
+	// Decoder
+	// decode request body into v, params is parameters of
+	// Content-Type header.
+	Decoder interface {
+		Decode(body io.Reader, params map[string]string, v interface{}) error
+	}
+
+	// DecoderFunc
+	// adapter to use function as Decoder.
+	DecoderFunc func(body io.Reader, params map[string]string, v interface{}) error
+
+	// DecodeError
+	// decode failure with position of problem.
+	//
+	//   errors.Is(err, request.ErrInvalidJson) // return true
+	DecodeError struct {
+		// Kind
+		// sentinel error of failure.
+		//
+		//   ErrInvalidJson, ErrInvalidXml, ErrInvalidForm,
+		//   ErrUnsupportedContentType
+		Kind error
+
+		// ContentType
+		// media type of body.
+		ContentType string
+
+		// Offset
+		// byte offset of body after which the failure occurred,
+		// zero if unknown.
+		Offset int64
+
+		// Line
+		// line number of body, zero if unknown.
+		Line int
+
+		// Field
+		// path of field failed.
+		//
+		//   "user.age"
+		Field string
+
+		// Expected
+		// type of field.
+		Expected string
+
+		// Value
+		// description of value in body.
+		//
+		//   "string", "number 1.5"
+		Value string
+
+		// Err
+		// error returned by underlying codec.
+		Err error
+	}
+)
+
+// Decode
+// call function.
+func (f DecoderFunc) Decode(body io.Reader, params map[string]string, v interface{}) error {
+	return f(body, params, v)
+}
+
+// Error
+// return failure message with position.
+//
+//   "invalid json: field 'age' expected int, got string at offset 12"
+func (o *DecodeError) Error() string {
+	s := o.Kind.Error()
+	switch {
+	case o.Field != "":
+		s += fmt.Sprintf(": field '%s'", o.Field)
+		if o.Expected != "" {
+			s += " expected " + o.Expected
+		}
+		if o.Value != "" {
+			s += ", got " + o.Value
+		}
+	case o.Err != nil:
+		s += ": " + o.Err.Error()
+	case o.ContentType != "":
+		s += ": " + o.ContentType
+	}
+	if o.Offset > 0 {
+		s += fmt.Sprintf(" at offset %d", o.Offset)
+	}
+	if o.Line > 0 {
+		s += fmt.Sprintf(" at line %d", o.Line)
+	}
+	return s
+}
+
+// Unwrap
+// return sentinel error of failure.
+func (o *DecodeError) Unwrap() error { return o.Kind }
+
+// Decode
+// decode body in content type and return validate result
+// translated in specified locale.
+//
+//   err := request.Validate.Decode(v, r.Header.Get("Content-Type"), r.Body, request.LocaleEN)
+//
+// Return DecodeError if body can not be decoded, or FieldErrors
+// if validation failed.
+func (o *Validator) Decode(v interface{}, contentType string, body io.Reader, locale string) error {
+	if err := o.decode(v, contentType, body); err != nil {
+		return err
+	}
+	return o.StructLocale(v, locale)
+}
+
+// Decoder
+// return decoder registered for media type of content type.
+//
+// Media type suffixed with +json or +xml use json or xml
+// decoder if not registered.
+func (o *Validator) Decoder(contentType string) (Decoder, bool) {
+	mt := mediaType(contentType)
+	if d, ok := o.decoders[mt]; ok {
+		return d, true
+	}
+	if i := strings.LastIndex(mt, "+"); i > 0 {
+		switch mt[i+1:] {
+		case "json":
+			d, ok := o.decoders[ContentTypeJson]
+			return d, ok
+		case "xml":
+			d, ok := o.decoders[ContentTypeXml]
+			return d, ok
+		}
+	}
+	return nil, false
+}
+
+// RegisterDecoder
+// register decoder for media type, built-in decoder is
+// replaced if registered already.
+//
+//   request.Validate.RegisterDecoder("application/yaml", request.DecoderFunc(
+//       func(body io.Reader, _ map[string]string, v interface{}) error {
+//           return yaml.NewDecoder(body).Decode(v)
+//       },
+//   ))
+func (o *Validator) RegisterDecoder(contentType string, decoder Decoder) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	o.decoders[mediaType(contentType)] = decoder
+	return nil
+}
+
+func (o *Validator) decode(v interface{}, contentType string, body io.Reader) error {
+	mt, params, _ := mime.ParseMediaType(contentType)
+	if contentType == "" {
+		mt = ContentTypeJson
+	}
+
+	d, ok := o.Decoder(mt)
+	if !ok {
+		return &DecodeError{Kind: ErrUnsupportedContentType, ContentType: mt}
+	}
+
+	if err := d.Decode(body, params, v); err != nil {
+		if de, ok := err.(*DecodeError); ok && de.ContentType == "" {
+			de.ContentType = mt
+		}
+		return err
+	}
+	return nil
+}
+
+func (o *Validator) initDecoders() {
+	o.decoders = map[string]Decoder{
+		ContentTypeForm:      DecoderFunc(decodeForm),
+		ContentTypeJson:      DecoderFunc(o.decodeJson),
+		ContentTypeMultipart: DecoderFunc(decodeMultipart),
+		ContentTypeXml:       DecoderFunc(decodeXml),
+		"text/xml":           DecoderFunc(decodeXml),
+	}
+}
+
+// /////////////////////////////////////////////////////////////
+// Built-in decoders.
+// /////////////////////////////////////////////////////////////
+
+func decodeForm(body io.Reader, _ map[string]string, v interface{}) error {
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(buf))
+	if err != nil {
+		return &DecodeError{Kind: ErrInvalidForm, Err: err}
+	}
+	return decodeValues(v, values, nil)
+}
+
+// Decode multipart
+// fill struct by multipart form. Files beyond DefaultMaxMemory
+// in total are stored in temporary files, they are removed once
+// decoded unless struct has a *multipart.Form field, which
+// receives the form and caller must call RemoveAll of it.
+//
+// Struct with *multipart.FileHeader fields must have the form
+// field, or ErrInvalidForm returned, as files of headers may be
+// removed before opened.
+//
+//   type Upload struct {
+//       Form   *multipart.Form
+//       Avatar *multipart.FileHeader `form:"avatar"`
+//   }
+//
+//   err := request.Validate.Decode(req, contentType, body, locale)
+//   defer req.Form.RemoveAll()
+func decodeMultipart(body io.Reader, params map[string]string, v interface{}) error {
+	boundary := params["boundary"]
+	if boundary == "" {
+		return &DecodeError{Kind: ErrInvalidForm, Err: fmt.Errorf("boundary not specified")}
+	}
+
+	// Files can not be opened once form removed.
+	if t := reflect.TypeOf(v); t != nil && hasFileField(t) && !hasFormField(t) {
+		return &DecodeError{Kind: ErrInvalidForm, Err: fmt.Errorf("%s has file fields but no *multipart.Form field", t)}
+	}
+
+	form, err := multipart.NewReader(body, boundary).ReadForm(DefaultMaxMemory)
+	if err != nil {
+		return &DecodeError{Kind: ErrInvalidForm, Err: err}
+	}
+	if !setMultipartForm(v, form) {
+		defer func() { _ = form.RemoveAll() }()
+	}
+	return decodeValues(v, form.Value, form.File)
+}
+
+func decodeXml(body io.Reader, _ map[string]string, v interface{}) error {
+	if err := xml.NewDecoder(body).Decode(v); err != nil {
+		de := &DecodeError{Kind: ErrInvalidXml, Err: err}
+		if e, ok := err.(*xml.SyntaxError); ok {
+			de.Line = e.Line
+		}
+		return de
+	}
+	return nil
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Decode values
+// fill struct fields by form tag, or json name if form tag not
+// defined. Field of *multipart.FileHeader type is filled by
+// uploaded files.
+func decodeValues(v interface{}, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return &DecodeError{Kind: ErrInvalidForm, Expected: "struct pointer", Err: fmt.Errorf("can not decode into %T", v)}
+	}
+	return decodeStruct(rv.Elem(), values, files)
+}
+
+func decodeStruct(rv reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		fv := rv.Field(i)
+
+		// Embedded struct.
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeStruct(fv, values, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		key := jsonName(f)
+		if tag, ok := f.Tag.Lookup("form"); ok {
+			if key = strings.Split(tag, ",")[0]; key == "" {
+				key = f.Name
+			}
+		}
+		if key == "-" {
+			continue
+		}
+
+		// Uploaded files.
+		switch f.Type {
+		case typeMultipartForm:
+			continue
+		case typeFileHeader:
+			if list := files[key]; len(list) > 0 {
+				fv.Set(reflect.ValueOf(list[0]))
+			}
+			continue
+		case reflect.SliceOf(typeFileHeader):
+			if list := files[key]; len(list) > 0 {
+				fv.Set(reflect.ValueOf(list))
+			}
+			continue
+		}
+
+		if list := values[key]; len(list) > 0 {
+			if err := convertValue(fv, list, f.Tag.Get("layout")); err != nil {
+				return &DecodeError{Kind: ErrInvalidForm, Field: key, Expected: typeName(f.Type), Value: fmt.Sprintf("%q", list[0]), Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// Has file field
+// return true if struct or embedded structs has field filled by
+// uploaded files.
+func hasFileField(t reflect.Type) bool {
+	if t = indirectType(t); t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if hasFileField(f.Type) {
+				return true
+			}
+			continue
+		}
+		if f.PkgPath == "" && (f.Type == typeFileHeader || f.Type == reflect.SliceOf(typeFileHeader)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Has form field
+// return true if struct has *multipart.Form field.
+func hasFormField(t reflect.Type) bool {
+	if t = indirectType(t); t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" && f.Type == typeMultipartForm {
+			return true
+		}
+	}
+	return false
+}
+
+// Set multipart form
+// into first *multipart.Form field of struct, return false if
+// struct has no such field.
+func setMultipartForm(v interface{}, form *multipart.Form) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		if f := rv.Type().Field(i); f.PkgPath == "" && f.Type == typeMultipartForm {
+			rv.Field(i).Set(reflect.ValueOf(form))
+			return true
+		}
+	}
+	return false
+}
+
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func newJsonError(err error) error {
+	de := &DecodeError{Kind: ErrInvalidJson, Err: err}
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		de.Offset = e.Offset
+	case *json.UnmarshalTypeError:
+		de.Offset = e.Offset
+		de.Field = e.Field
+		de.Expected = e.Type.String()
+		de.Value = e.Value
+	}
+	return de
+}
@@ -0,0 +1,226 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	decodeReq struct {
+		XMLName xml.Name `json:"-" xml:"user" form:"-"`
+		Name    string   `json:"name" xml:"name" form:"name" label:"Name" validate:"required"`
+		Age     int      `json:"age" xml:"age" form:"age"`
+		Tags    []string `json:"tags" xml:"tag" form:"tag"`
+	}
+
+	uploadReq struct {
+		Form   *multipart.Form
+		Name   string                `form:"name"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+
+	uploadBase struct {
+		Photos []*multipart.FileHeader `form:"photos"`
+	}
+
+	uploadNoFormReq struct {
+		uploadBase
+		Name string `form:"name"`
+	}
+)
+
+func TestValidator_Decode(t *testing.T) {
+	form, formType := newMultipart(t, map[string]string{"name": "alice", "age": "18", "tag": "a,b"}, nil)
+
+	for _, c := range []struct {
+		contentType, body string
+	}{
+		{"", `{"name":"alice","age":18,"tags":["a","b"]}`},
+		{"application/json; charset=utf-8", `{"name":"alice","age":18,"tags":["a","b"]}`},
+		{"application/vnd.api+json", `{"name":"alice","age":18,"tags":["a","b"]}`},
+		{"application/xml", `<user><name>alice</name><age>18</age><tag>a</tag><tag>b</tag></user>`},
+		{"text/xml", `<user><name>alice</name><age>18</age><tag>a</tag><tag>b</tag></user>`},
+		{"application/atom+xml", `<user><name>alice</name><age>18</age><tag>a</tag><tag>b</tag></user>`},
+		{"application/x-www-form-urlencoded", `name=alice&age=18&tag=a&tag=b`},
+		{formType, form},
+	} {
+		req := &decodeReq{}
+		if err := request.Validate.Decode(req, c.contentType, strings.NewReader(c.body), request.LocaleEN); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.contentType, err)
+			continue
+		}
+		if req.Name != "alice" || req.Age != 18 || !reflect.DeepEqual(req.Tags, []string{"a", "b"}) {
+			t.Errorf("%s: unexpected result: %+v", c.contentType, req)
+		}
+	}
+
+	// Validated after decoded.
+	err := request.Validate.Decode(&decodeReq{}, "application/json", strings.NewReader(`{"age":1}`), request.LocaleEN)
+	if errs, ok := err.(request.FieldErrors); !ok || len(errs) != 1 || errs[0].Field != "name" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidator_Decode_Errors(t *testing.T) {
+	for _, c := range []struct {
+		contentType, body string
+		kind              error
+		expected          request.DecodeError
+	}{
+		{"application/json", `{"name":"alice",}`, request.ErrInvalidJson, request.DecodeError{Offset: 17}},
+		{"application/json", `{"name":"alice","age":"18"}`, request.ErrInvalidJson, request.DecodeError{Offset: 26, Field: "age", Expected: "int", Value: "string"}},
+		{"application/json", `{"name":"alice"} {}`, request.ErrInvalidJson, request.DecodeError{Offset: 18}},
+		{"application/xml", "<user>\n<name>alice</user>", request.ErrInvalidXml, request.DecodeError{Line: 2}},
+		{"application/x-www-form-urlencoded", `name=alice&age=x`, request.ErrInvalidForm, request.DecodeError{Field: "age", Expected: "int", Value: `"x"`}},
+		{"application/x-www-form-urlencoded", `name=%zz`, request.ErrInvalidForm, request.DecodeError{}},
+		{"multipart/form-data", `name=alice`, request.ErrInvalidForm, request.DecodeError{}},
+		{"application/yaml", `name: alice`, request.ErrUnsupportedContentType, request.DecodeError{}},
+	} {
+		err := request.Validate.Decode(&decodeReq{}, c.contentType, strings.NewReader(c.body), request.LocaleEN)
+		if !errors.Is(err, c.kind) {
+			t.Errorf("%s %s: expected %v, got %v", c.contentType, c.body, c.kind, err)
+			continue
+		}
+
+		de := &request.DecodeError{}
+		if !errors.As(err, &de) {
+			t.Errorf("%s %s: expected DecodeError, got %T", c.contentType, c.body, err)
+			continue
+		}
+		if de.Offset != c.expected.Offset || de.Line != c.expected.Line || de.Field != c.expected.Field ||
+			de.Expected != c.expected.Expected || de.Value != c.expected.Value {
+			t.Errorf("%s %s: unexpected position: %+v", c.contentType, c.body, de)
+		}
+		if de.ContentType == "" {
+			t.Errorf("%s %s: content type not set: %v", c.contentType, c.body, de)
+		}
+	}
+}
+
+func TestValidator_Decoder(t *testing.T) {
+	yaml := request.DecoderFunc(func(body io.Reader, params map[string]string, v interface{}) error {
+		buf, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		v.(*decodeReq).Name = strings.TrimPrefix(string(buf), "name: ") + params["charset"]
+		return nil
+	})
+
+	v, err := request.NewValidator(request.WithDecoder("application/yaml", yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := v.Decoder("application/yaml; charset=utf-8"); !ok {
+		t.Errorf("registered decoder not found")
+	}
+	if _, ok := request.Validate.Decoder("application/yaml"); ok {
+		t.Errorf("decoder registered on default validator")
+	}
+	for _, ct := range []string{"application/json", "application/problem+json", "text/xml", "application/rss+xml", "multipart/form-data"} {
+		if _, ok := v.Decoder(ct); !ok {
+			t.Errorf("%s: built-in decoder not found", ct)
+		}
+	}
+
+	req := &decodeReq{}
+	if err = v.Decode(req, "application/yaml; charset=utf-8", strings.NewReader("name: alice"), request.LocaleEN); err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "aliceutf-8" {
+		t.Errorf("unexpected name: %s", req.Name)
+	}
+}
+
+func TestValidator_Decode_Multipart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "multipart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	defer func(s string) { _ = os.Setenv("TMPDIR", s) }(os.Getenv("TMPDIR"))
+	_ = os.Setenv("TMPDIR", dir)
+
+	// File larger than memory is stored in temporary file.
+	large := strings.Repeat("x", request.DefaultMaxMemory+1)
+	tmpFiles := func() int {
+		list, _ := ioutil.ReadDir(dir)
+		return len(list)
+	}
+
+	// Form handed to struct, caller removes temporary files.
+	body, ct := newMultipart(t, map[string]string{"name": "alice"}, map[string]string{"avatar": large})
+	req := &uploadReq{}
+	if err = request.Validate.Decode(req, ct, strings.NewReader(body), request.LocaleEN); err != nil {
+		t.Fatal(err)
+	}
+	if req.Form == nil || req.Name != "alice" || req.Avatar == nil || req.Avatar.Size != int64(len(large)) {
+		t.Fatalf("unexpected result: %+v", req)
+	}
+	if f, err := req.Avatar.Open(); err != nil {
+		t.Errorf("open uploaded file: %v", err)
+	} else {
+		_ = f.Close()
+	}
+	if n := tmpFiles(); n != 1 {
+		t.Errorf("expected 1 temporary file, got %d", n)
+	}
+	if err = req.Form.RemoveAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Temporary files removed once decoded.
+	if err = request.Validate.Decode(&decodeReq{}, ct, strings.NewReader(body), request.LocaleEN); err != nil {
+		t.Fatal(err)
+	}
+	if n := tmpFiles(); n != 0 {
+		t.Errorf("expected temporary files removed, got %d", n)
+	}
+
+	// File fields without form field, files would be removed
+	// before opened.
+	err = request.Validate.Decode(&uploadNoFormReq{}, ct, strings.NewReader(body), request.LocaleEN)
+	if !errors.Is(err, request.ErrInvalidForm) {
+		t.Errorf("expected ErrInvalidForm, got %v", err)
+	}
+	if n := tmpFiles(); n != 0 {
+		t.Errorf("expected no temporary file, got %d", n)
+	}
+}
+
+func newMultipart(t *testing.T, values, files map[string]string) (body, contentType string) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for key, value := range values {
+		if err := w.WriteField(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for key, content := range files {
+		fw, err := w.CreateFormFile(key, key+".png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String(), w.FormDataContentType()
+}
@@ -0,0 +1,184 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"reflect"
+	"strings"
+
+	i18nValidator "github.com/go-playground/validator/v10"
+
+	"github.com/fuyibing/util/v8/web/response"
+)
+
+type (
+	// FieldError
+	// validation failure of struct field.
+	FieldError struct {
+		// Namespace
+		// path of struct field.
+		//
+		//   "User.Addresses[0].City"
+		Namespace string `json:"namespace" label:"Namespace"`
+
+		// Field
+		// path of json field.
+		//
+		//   "addresses[0].city"
+		Field string `json:"field" label:"Field"`
+
+		Label   string      `json:"label" label:"Label"`
+		Tag     string      `json:"tag" label:"Validation tag"`
+		Param   string      `json:"param" label:"Validation param"`
+		Value   interface{} `json:"value" label:"Value"`
+		Message string      `json:"message" label:"Message"`
+	}
+
+	// FieldErrors
+	// validation failures of all invalid fields.
+	FieldErrors []*FieldError
+)
+
+// Error
+// return translated messages joined by semicolon.
+func (o FieldErrors) Error() string {
+	list := make([]string, 0, len(o))
+	for _, e := range o {
+		list = append(list, e.Message)
+	}
+	return strings.Join(list, "; ")
+}
+
+// Result
+// return error result with failures of all fields.
+//
+//   return {
+//       "data": {
+//           "fields": [
+//               {
+//                   "namespace": "User.Name",
+//                   "field": "name",
+//                   "label": "Name",
+//                   "tag": "required",
+//                   "param": "",
+//                   "value": "",
+//                   "message": "Name is a required field"
+//               }
+//           ]
+//       },
+//       "data_type": "ERROR",
+//       "errno": 1,
+//       "error": "Name is a required field"
+//   }
+func (o FieldErrors) Result(code int) *response.Result {
+	r := response.With.ErrorCode(o, code)
+	r.Data = map[string]interface{}{response.ResultFieldForFields: o}
+	return r
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+func newFieldErrors(v interface{}, errs i18nValidator.ValidationErrors, translate func(fe i18nValidator.FieldError) string) FieldErrors {
+	list := make(FieldErrors, 0, len(errs))
+	for _, fe := range errs {
+		list = append(list, &FieldError{
+			Namespace: fe.StructNamespace(),
+			Field:     jsonPath(reflect.TypeOf(v), fe.StructNamespace()),
+			Label:     fe.Field(),
+			Tag:       fe.Tag(),
+			Param:     fe.Param(),
+			Value:     fe.Value(),
+			Message:   translate(fe),
+		})
+	}
+	return list
+}
+
+// Json path
+// convert struct namespace into json path, segments of embedded
+// structs are dropped as encoding/json flattens them.
+//
+//   "User.Addresses[0].City" => "addresses[0].city"
+//   "User.Base.Name"         => "name"
+func jsonPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) < 2 {
+		return namespace
+	}
+
+	list := make([]string, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		name, index := segment, ""
+		if i := strings.Index(segment, "["); i > 0 {
+			name, index = segment[:i], segment[i:]
+		}
+
+		// Resolve json name by struct field.
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t != nil && t.Kind() == reflect.Struct {
+			if f, ok := t.FieldByName(name); ok {
+				if t = f.Type; embedded(f) && index == "" {
+					continue
+				}
+				name = jsonName(f)
+			} else {
+				t = nil
+			}
+		} else {
+			t = nil
+		}
+
+		// Element type of slice, array or map.
+		for n := strings.Count(index, "["); n > 0 && t != nil; n-- {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				t = nil
+			}
+		}
+
+		list = append(list, name+index)
+	}
+	return strings.Join(list, ".")
+}
+
+// Embedded
+// return true if field is embedded struct flattened by
+// encoding/json, it is anonymous and has no json name.
+func embedded(f reflect.StructField) bool {
+	if !f.Anonymous {
+		return false
+	}
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" {
+		return false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// Json name
+// return json name of struct field, or key of request source
+// if json tag not defined, return field name if neither
+// defined.
+func jsonName(f reflect.StructField) string {
+	for _, key := range append([]string{"json"}, bindSources...) {
+		if tag := f.Tag.Get(key); tag != "" {
+			if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return f.Name
+}
@@ -0,0 +1,84 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+	"github.com/fuyibing/util/v8/web/response"
+)
+
+type (
+	errorsBase struct {
+		Name string `json:"name" label:"Name" validate:"max=3"`
+	}
+
+	errorsAddress struct {
+		City string `json:"city" label:"City" validate:"required"`
+	}
+
+	errorsReq struct {
+		errorsBase
+		Owner     *errorsBase      `json:"owner" validate:"omitempty"`
+		Addresses []*errorsAddress `json:"addresses" validate:"dive"`
+	}
+)
+
+func TestFieldErrors(t *testing.T) {
+	err := request.Validate.StructLocale(&errorsReq{
+		errorsBase: errorsBase{Name: "toolong"},
+		Owner:      &errorsBase{Name: "toolong"},
+		Addresses:  []*errorsAddress{{City: "Shanghai"}, {}},
+	}, request.LocaleEN)
+
+	errs, ok := err.(request.FieldErrors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, c := range []struct {
+		namespace, field, tag string
+	}{
+		{"errorsReq.errorsBase.Name", "name", "max"},
+		{"errorsReq.Owner.Name", "owner.name", "max"},
+		{"errorsReq.Addresses[1].City", "addresses[1].city", "required"},
+	} {
+		if e := errs[i]; e.Namespace != c.namespace || e.Field != c.field || e.Tag != c.tag {
+			t.Errorf("%d: unexpected failure: %s %s %s", i, e.Namespace, e.Field, e.Tag)
+		}
+	}
+	if s := errs[2].Message; s != "City is a required field" {
+		t.Errorf("unexpected message: %s", s)
+	}
+}
+
+func TestFieldErrors_Result(t *testing.T) {
+	errs := request.Validate.StructLocale(&errorsReq{Addresses: []*errorsAddress{{}}}, request.LocaleEN).(request.FieldErrors)
+
+	buf, err := json.Marshal(errs.Result(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &struct {
+		Data struct {
+			Fields []map[string]interface{} `json:"fields"`
+		} `json:"data"`
+		DataType string `json:"data_type"`
+		Errno    int    `json:"errno"`
+		Error    string `json:"error"`
+	}{}
+	if err = json.Unmarshal(buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.DataType != string(response.TypeError) || result.Errno != 2 || result.Error != errs.Error() {
+		t.Errorf("unexpected result: %s", buf)
+	}
+	if len(result.Data.Fields) != 1 || result.Data.Fields[0]["field"] != "addresses[0].city" {
+		t.Errorf("unexpected fields: %s", buf)
+	}
+}
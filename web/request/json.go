@@ -0,0 +1,387 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrBodyTooLarge = fmt.Errorf("body too large")
+	ErrDuplicateKey = fmt.Errorf("duplicate key")
+	ErrMaxDepth     = fmt.Errorf("max depth exceeded")
+	ErrUnknownField = fmt.Errorf("unknown field")
+
+	typeJsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+	// Json fields of struct types.
+	jsonFieldCache sync.Map
+)
+
+type (
+	// JSONOptions
+	// options of json decoder, zero value is compatible with
+	// json.Unmarshal.
+	//
+	//   request.Validate.SetJSONOptions(request.JSONOptions{
+	//       DisallowDuplicateKeys: true,
+	//       DisallowUnknownFields: true,
+	//       MaxBytes:              1 << 20,
+	//       MaxDepth:              32,
+	//   })
+	JSONOptions struct {
+		// DisallowDuplicateKeys
+		// return ErrDuplicateKey if object contains same key
+		// more than once.
+		DisallowDuplicateKeys bool
+
+		// DisallowUnknownFields
+		// return ErrUnknownField if object contains key not
+		// matched any struct field.
+		DisallowUnknownFields bool
+
+		// MaxBytes
+		// return ErrBodyTooLarge if body larger than it, zero
+		// means unlimited.
+		MaxBytes int64
+
+		// MaxDepth
+		// return ErrMaxDepth if objects and arrays nested deeper
+		// than it, zero means unlimited.
+		MaxDepth int
+
+		// UseNumber
+		// decode number into json.Number rather than float64
+		// for interface{} fields.
+		UseNumber bool
+	}
+
+	jsonField struct {
+		name  string
+		field reflect.StructField
+	}
+
+	jsonFrame struct {
+		child   reflect.Type
+		expect  bool
+		ignored bool
+		index   int
+		key     string
+		keys    map[string]bool
+		object  bool
+		path    string
+		t       reflect.Type
+		unknown bool
+	}
+)
+
+// JSONOptions
+// return options of json decoder.
+func (o *Validator) JSONOptions() JSONOptions { return o.json }
+
+// SetJSONOptions
+// set options of json decoder, used by Body and json body of
+// Decode and Bind.
+func (o *Validator) SetJSONOptions(opts JSONOptions) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	o.json = opts
+	return nil
+}
+
+func (o *Validator) decodeJson(body io.Reader, _ map[string]string, v interface{}) error {
+	opts := o.json
+
+	// Read body with limitation.
+	if opts.MaxBytes > 0 {
+		body = io.LimitReader(body, opts.MaxBytes+1)
+	}
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if opts.MaxBytes > 0 && int64(len(buf)) > opts.MaxBytes {
+		return &DecodeError{Kind: ErrBodyTooLarge, Offset: opts.MaxBytes}
+	}
+
+	// Scan tokens before decode.
+	if opts.DisallowDuplicateKeys || opts.DisallowUnknownFields || opts.MaxDepth > 0 {
+		if err = scanJson(buf, opts, reflect.TypeOf(v), nil); err != nil {
+			return err
+		}
+	}
+
+	r := bytes.NewReader(buf)
+	dec := json.NewDecoder(r)
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+	if err = dec.Decode(v); err != nil {
+		return newJsonError(err)
+	}
+
+	// Trailing data.
+	if _, err = dec.Token(); err != io.EOF {
+		return &DecodeError{Kind: ErrInvalidJson, Offset: jsonOffset(dec, r), Err: fmt.Errorf("invalid data after top-level value")}
+	}
+	return nil
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Scan json
+// walk tokens of body, return error if depth exceeded, duplicate
+// key found, or key not matched any field of type t which body
+// is decoded into.
+//
+// Visit is called with path of each value decoded into type t,
+// values of unknown keys are not visited.
+func scanJson(buf []byte, opts JSONOptions, t reflect.Type, visit func(path string)) error {
+	r := bytes.NewReader(buf)
+	dec := json.NewDecoder(r)
+	stack := make([]*jsonFrame, 0)
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newJsonError(err)
+		}
+
+		// End of object or array.
+		if token == json.Delim('}') || token == json.Delim(']') {
+			stack = stack[:len(stack)-1]
+			if n := len(stack); n > 0 {
+				stack[n-1].done()
+			}
+			continue
+		}
+
+		// Key of object, or path of value.
+		path, ignored := "", false
+		if n := len(stack); n > 0 {
+			top := stack[n-1]
+			if top.object && top.expect {
+				// Keys matched same struct field are duplicated.
+				err = top.field(token.(string))
+				if opts.DisallowDuplicateKeys && top.keys[top.key] {
+					return &DecodeError{Kind: ErrDuplicateKey, Offset: jsonOffset(dec, r), Field: top.keyPath()}
+				}
+				top.keys[top.key] = true
+				if err != nil && opts.DisallowUnknownFields {
+					return &DecodeError{Kind: ErrUnknownField, Offset: jsonOffset(dec, r), Field: top.keyPath()}
+				}
+				continue
+			}
+			path, t = top.value(), top.child
+			if ignored = top.ignored || top.unknown; !ignored && visit != nil {
+				visit(path)
+			}
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if opts.MaxDepth > 0 && len(stack) >= opts.MaxDepth {
+				return &DecodeError{Kind: ErrMaxDepth, Offset: jsonOffset(dec, r), Field: path}
+			}
+			object := token == json.Delim('{')
+			stack = append(stack, &jsonFrame{expect: object, index: -1, keys: make(map[string]bool), object: object, path: path, t: jsonType(t, object), ignored: ignored})
+		default:
+			if n := len(stack); n > 0 {
+				stack[n-1].done()
+			}
+		}
+	}
+}
+
+// Json fields
+// return fields of struct decoded by encoding/json in order,
+// fields of embedded structs are flattened and shallower field
+// is preferred for the same name.
+func jsonFields(t reflect.Type) []jsonField {
+	if list, ok := jsonFieldCache.Load(t); ok {
+		return list.([]jsonField)
+	}
+
+	var (
+		list    = make([]jsonField, 0)
+		names   = make(map[string]bool)
+		current = []reflect.Type{t}
+		visited = make(map[reflect.Type]bool)
+	)
+	for len(current) > 0 {
+		next, level := make([]reflect.Type, 0), make([]jsonField, 0)
+		for _, st := range current {
+			if visited[st] {
+				continue
+			}
+			visited[st] = true
+
+			for i := 0; i < st.NumField(); i++ {
+				f := st.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name := strings.Split(tag, ",")[0]
+				if name == "" && embedded(f) {
+					if !skipField(f) {
+						next = append(next, indirectType(f.Type))
+					}
+					continue
+				}
+				if f.PkgPath != "" {
+					continue
+				}
+				if name == "" {
+					name = f.Name
+				}
+				level = append(level, jsonField{name: name, field: f})
+			}
+		}
+		for _, jf := range level {
+			if !names[jf.name] {
+				names[jf.name] = true
+				list = append(list, jf)
+			}
+		}
+		current = next
+	}
+
+	jsonFieldCache.Store(t, list)
+	return list
+}
+
+// Json type
+// return struct, map, slice or array type decoded from json
+// object or array, return nil if value is decoded by Unmarshaler,
+// into interface, or type not matched.
+func jsonType(t reflect.Type, object bool) reflect.Type {
+	for t != nil {
+		if t.Implements(typeJsonUnmarshaler) || reflect.PtrTo(t).Implements(typeJsonUnmarshaler) {
+			return nil
+		}
+		if t.Kind() != reflect.Ptr {
+			break
+		}
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		if object {
+			return t
+		}
+	case reflect.Slice, reflect.Array:
+		if !object {
+			return t
+		}
+	}
+	return nil
+}
+
+// Lookup json field
+// return struct field matched by json key, exactly matched name
+// is preferred, then case-insensitive matched as encoding/json.
+func lookupJsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	fields := jsonFields(t)
+	for _, jf := range fields {
+		if jf.name == key {
+			return jf.field, true
+		}
+	}
+	for _, jf := range fields {
+		if strings.EqualFold(jf.name, key) {
+			return jf.field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Json offset
+// return offset of decoder reading from r, it is same as
+// InputOffset of json.Decoder which requires go 1.14.
+func jsonOffset(dec *json.Decoder, r *bytes.Reader) int64 {
+	buffered, _ := io.Copy(ioutil.Discard, dec.Buffered())
+	return r.Size() - int64(r.Len()) - buffered
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// /////////////////////////////////////////////////////////////
+// Frame methods.
+// /////////////////////////////////////////////////////////////
+
+// Done
+// value of frame completed, object expects next key.
+func (o *jsonFrame) done() { o.expect = o.object }
+
+// Field
+// resolve key of object, path segment of field is json name
+// of struct field. Return ErrUnknownField if key not matched
+// any field of struct.
+func (o *jsonFrame) field(key string) error {
+	o.child, o.expect, o.key, o.unknown = nil, false, key, false
+	if o.t == nil {
+		return nil
+	}
+
+	switch o.t.Kind() {
+	case reflect.Map:
+		o.child = o.t.Elem()
+	case reflect.Struct:
+		f, ok := lookupJsonField(o.t, key)
+		if !ok {
+			o.unknown = true
+			return ErrUnknownField
+		}
+		o.child, o.key = f.Type, jsonName(f)
+	}
+	return nil
+}
+
+// Key path
+// return path of value of current key, key of map is used as
+// index as json path of field error.
+//
+//   "labels[red]"
+func (o *jsonFrame) keyPath() string {
+	if o.t != nil && o.t.Kind() == reflect.Map {
+		return fmt.Sprintf("%s[%s]", o.path, o.key)
+	}
+	return joinPath(o.path, o.key)
+}
+
+// Value
+// return path of value started in frame.
+func (o *jsonFrame) value() string {
+	if o.object {
+		return o.keyPath()
+	}
+	if o.child = nil; o.t != nil {
+		o.child = o.t.Elem()
+	}
+	o.index++
+	return fmt.Sprintf("%s[%d]", o.path, o.index)
+}
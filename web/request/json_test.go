@@ -0,0 +1,187 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	jsonBase struct {
+		Id int `json:"id"`
+	}
+
+	jsonItem struct {
+		Sku string `json:"sku"`
+	}
+
+	jsonReq struct {
+		jsonBase
+		Name    string                 `json:"name"`
+		Age     int                    `json:"age,omitempty"`
+		Items   []jsonItem             `json:"items"`
+		Labels  map[string]jsonItem    `json:"labels"`
+		Created time.Time              `json:"created"`
+		Raw     json.RawMessage        `json:"raw"`
+		Extra   map[string]interface{} `json:"extra"`
+		Value   interface{}            `json:"value"`
+	}
+
+	// Reader of endless json string, counts bytes read.
+	endlessReader struct {
+		n int
+	}
+)
+
+func (o *endlessReader) Read(p []byte) (int, error) {
+	if o.n == 0 && len(p) > 0 {
+		p[0], o.n = '"', 1
+		return 1, nil
+	}
+	for i := range p {
+		p[i] = 'x'
+	}
+	o.n += len(p)
+	return len(p), nil
+}
+
+func TestJSONOptions(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		opts  request.JSONOptions
+		body  string
+		kind  error
+		field string
+	}{
+		// Zero options are compatible with json.Unmarshal.
+		{"compatible", request.JSONOptions{}, `{"name":"a","name":"b","unknown":1,"items":[{"sku":"a","sku":"b"}]}`, nil, ""},
+
+		{"unknown field", request.JSONOptions{DisallowUnknownFields: true}, `{"name":"a","unknown":1}`, request.ErrUnknownField, "unknown"},
+		{"unknown nested field", request.JSONOptions{DisallowUnknownFields: true}, `{"items":[{"sku":"a"},{"price":1}]}`, request.ErrUnknownField, "items[1].price"},
+		{"unknown map value field", request.JSONOptions{DisallowUnknownFields: true}, `{"labels":{"x":{"color":1}}}`, request.ErrUnknownField, "labels[x].color"},
+		{"known fields", request.JSONOptions{DisallowUnknownFields: true},
+			`{"ID":1,"NAME":"a","items":[{"SKU":"a"}],"created":"2023-03-01T00:00:00Z","raw":{"x":1},"extra":{"x":{"y":1}},"value":{"x":1}}`, nil, ""},
+
+		{"duplicate key", request.JSONOptions{DisallowDuplicateKeys: true}, `{"name":"a","name":"b"}`, request.ErrDuplicateKey, "name"},
+		{"duplicate nested key", request.JSONOptions{DisallowDuplicateKeys: true}, `{"items":[{"sku":"a","sku":"b"}]}`, request.ErrDuplicateKey, "items[0].sku"},
+		{"duplicate field", request.JSONOptions{DisallowDuplicateKeys: true}, `{"name":"a","Name":"b"}`, request.ErrDuplicateKey, "name"},
+		{"duplicate embedded field", request.JSONOptions{DisallowDuplicateKeys: true}, `{"ID":1,"id":2}`, request.ErrDuplicateKey, "id"},
+		{"duplicate map key", request.JSONOptions{DisallowDuplicateKeys: true}, `{"labels":{"x":{},"x":{}}}`, request.ErrDuplicateKey, "labels[x]"},
+		{"map keys case sensitive", request.JSONOptions{DisallowDuplicateKeys: true}, `{"labels":{"x":{},"X":{}}}`, nil, ""},
+		{"different objects", request.JSONOptions{DisallowDuplicateKeys: true}, `{"items":[{"sku":"a"},{"sku":"b"}]}`, nil, ""},
+
+		{"too large", request.JSONOptions{MaxBytes: 16}, `{"name":"alice and bob"}`, request.ErrBodyTooLarge, ""},
+		{"not too large", request.JSONOptions{MaxBytes: 16}, `{"name":"alice"}`, nil, ""},
+
+		{"max depth", request.JSONOptions{MaxDepth: 2}, `{"items":[{"sku":"a"}]}`, request.ErrMaxDepth, "items[0]"},
+		{"not max depth", request.JSONOptions{MaxDepth: 3}, `{"items":[{"sku":"a"}]}`, nil, ""},
+	} {
+		v, err := request.NewValidator(request.WithJSONOptions(c.opts))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = v.Decode(&jsonReq{}, request.ContentTypeJson, strings.NewReader(c.body), request.LocaleEN)
+		if c.kind == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, err)
+			}
+			continue
+		}
+
+		de := &request.DecodeError{}
+		if !errors.Is(err, c.kind) || !errors.As(err, &de) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.kind, err)
+			continue
+		}
+		if de.Field != c.field {
+			t.Errorf("%s: expected field %s, got %s", c.name, c.field, de.Field)
+		}
+	}
+}
+
+func TestJSONOptions_Offset(t *testing.T) {
+	for _, c := range []struct {
+		opts   request.JSONOptions
+		body   string
+		offset int64
+	}{
+		{request.JSONOptions{DisallowUnknownFields: true}, `{"name":"a","unknown":1}`, 21},
+		{request.JSONOptions{DisallowUnknownFields: true}, `{"items":[{"sku":"a"},{"price":1}]}`, 30},
+		{request.JSONOptions{DisallowDuplicateKeys: true}, `{"name":"a", "name":"b"}`, 19},
+		{request.JSONOptions{MaxDepth: 2}, `{"items":[ {"sku":"a"}]}`, 12},
+		{request.JSONOptions{}, `{"name":"a"}  {}`, 15},
+	} {
+		v, err := request.NewValidator(request.WithJSONOptions(c.opts))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		de := &request.DecodeError{}
+		if err = v.Decode(&jsonReq{}, request.ContentTypeJson, strings.NewReader(c.body), request.LocaleEN); !errors.As(err, &de) {
+			t.Errorf("%s: expected DecodeError, got %v", c.body, err)
+			continue
+		}
+		if de.Offset != c.offset {
+			t.Errorf("%s: expected offset %d, got %d", c.body, c.offset, de.Offset)
+		}
+	}
+}
+
+func TestJSONOptions_UseNumber(t *testing.T) {
+	for _, c := range []struct {
+		opts     request.JSONOptions
+		expected interface{}
+	}{
+		{request.JSONOptions{}, float64(12345678901234567)},
+		{request.JSONOptions{UseNumber: true}, json.Number("12345678901234567")},
+	} {
+		v, err := request.NewValidator(request.WithJSONOptions(c.opts))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := &jsonReq{}
+		if err = v.Decode(req, request.ContentTypeJson, strings.NewReader(`{"value":12345678901234567}`), request.LocaleEN); err != nil {
+			t.Fatal(err)
+		}
+		if req.Value != c.expected {
+			t.Errorf("expected %T %v, got %T %v", c.expected, c.expected, req.Value, req.Value)
+		}
+	}
+}
+
+func TestJSONOptions_Bind(t *testing.T) {
+	v, err := request.NewValidator(request.WithJSONOptions(request.JSONOptions{MaxBytes: 1 << 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Body is not read into memory beyond limitation.
+	body := &endlessReader{}
+	r := httptest.NewRequest(http.MethodPost, "/", ioutil.NopCloser(body))
+	r.Header.Set("Content-Type", request.ContentTypeJson)
+	if err = v.Bind(r, &jsonReq{}); !errors.Is(err, request.ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if body.n > 1<<13 {
+		t.Errorf("read %d bytes of body", body.n)
+	}
+
+	// Body with spaces only is empty.
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(" \n\t"))
+	r.Header.Set("Content-Type", request.ContentTypeJson)
+	if err = v.Bind(r, &jsonReq{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	i18nTranslator "github.com/go-playground/universal-translator"
+)
+
+const (
+	LocaleEN     = "en"
+	LocaleZH     = "zh"
+	LocaleZHHant = "zh_Hant"
+)
+
+var (
+	localeKey = &localeContextKey{}
+
+	// Chinese regions use traditional script.
+	localeHantRegions = map[string]bool{"tw": true, "hk": true, "mo": true}
+)
+
+type (
+	// Language
+	// item of Accept-Language header.
+	Language struct {
+		Tag string
+		Q   float64
+	}
+
+	localeContextKey struct{}
+)
+
+// LocaleFromContext
+// return locale carried by ctx, return empty string if not
+// carried.
+func LocaleFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if s, ok := ctx.Value(localeKey).(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// NewLocaleContext
+// return context carries locale.
+func NewLocaleContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// ParseAcceptLanguage
+// return languages of Accept-Language header sorted by quality
+// descending, languages with zero quality are ignored.
+//
+//   ParseAcceptLanguage("zh-TW,zh;q=0.9,en;q=0.8")
+//   // return [{zh-TW 1} {zh 0.9} {en 0.8}]
+func ParseAcceptLanguage(header string) []Language {
+	list := make([]Language, 0)
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ";")
+		if parts[0] == "" {
+			continue
+		}
+
+		lang := Language{Tag: strings.TrimSpace(parts[0]), Q: 1}
+		for _, param := range parts[1:] {
+			if param = strings.TrimSpace(param); strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					lang.Q = q
+				}
+			}
+		}
+		if lang.Q > 0 {
+			list = append(list, lang)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Q > list[j].Q })
+	return list
+}
+
+// Match locale
+// return registered locale for language tag.
+//
+// Chinese tags fall back between scripts by region, zh-TW,
+// zh-HK and zh-MO use zh_Hant, others use zh. Other tags fall
+// back to primary language.
+func matchLocale(tag string, trans map[string]i18nTranslator.Translator) (string, bool) {
+	normalized := normalizeLocale(tag)
+
+	// Exactly matched.
+	for locale := range trans {
+		if normalizeLocale(locale) == normalized {
+			return locale, true
+		}
+	}
+
+	subtags := strings.Split(normalized, "-")
+	if subtags[0] == "zh" && len(subtags) > 1 {
+		script := LocaleZH
+		for _, subtag := range subtags[1:] {
+			if subtag == "hant" || localeHantRegions[subtag] {
+				script = LocaleZHHant
+			}
+			if subtag == "hans" {
+				script = LocaleZH
+				break
+			}
+		}
+		if _, ok := trans[script]; ok {
+			return script, true
+		}
+	}
+
+	// Primary language.
+	for locale := range trans {
+		if normalizeLocale(locale) == subtags[0] {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+func normalizeLocale(tag string) string {
+	return strings.ToLower(strings.Replace(tag, "_", "-", -1))
+}
@@ -0,0 +1,78 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for _, c := range []struct {
+		header, expected string
+	}{
+		{"", "[]"},
+		{"en", "[{en 1}]"},
+		{"zh-TW,zh;q=0.9,en;q=0.8", "[{zh-TW 1} {zh 0.9} {en 0.8}]"},
+		{"en;q=0.5, zh-CN ;q=0.7,fr", "[{fr 1} {zh-CN 0.7} {en 0.5}]"},
+		{"de;q=0,en;q=invalid,*;q=0.1", "[{en 1} {* 0.1}]"},
+		{"ja;q=0.8,ko;q=0.8", "[{ja 0.8} {ko 0.8}]"},
+	} {
+		if s := fmt.Sprintf("%v", request.ParseAcceptLanguage(c.header)); s != c.expected {
+			t.Errorf("%q: got %s, expected %s", c.header, s, c.expected)
+		}
+	}
+}
+
+func TestValidator_Negotiate(t *testing.T) {
+	for _, c := range []struct {
+		header, expected string
+	}{
+		// Exactly matched and q-values.
+		{"en", request.LocaleEN},
+		{"zh", request.LocaleZH},
+		{"zh_Hant", request.LocaleZHHant},
+		{"en;q=0.5,zh;q=0.8", request.LocaleZH},
+		{"zh;q=0,en", request.LocaleEN},
+
+		// Chinese scripts by region.
+		{"zh-CN", request.LocaleZH},
+		{"zh-TW", request.LocaleZHHant},
+		{"zh-HK", request.LocaleZHHant},
+		{"zh-MO", request.LocaleZHHant},
+		{"zh-Hant", request.LocaleZHHant},
+		{"zh-Hant-CN", request.LocaleZHHant},
+		{"zh-Hans-HK", request.LocaleZH},
+		{"ZH-tw", request.LocaleZHHant},
+
+		// Primary language.
+		{"en-US,en;q=0.9", request.LocaleEN},
+		{"en-GB", request.LocaleEN},
+
+		// Unknown tags and wildcard use default locale.
+		{"", request.LocaleZH},
+		{"fr", request.LocaleZH},
+		{"fr,en;q=0.5", request.LocaleEN},
+		{"fr,*;q=0.5,en;q=0.1", request.LocaleZH},
+		{"*", request.LocaleZH},
+	} {
+		if s := request.Validate.Negotiate(c.header); s != c.expected {
+			t.Errorf("%q: got %s, expected %s", c.header, s, c.expected)
+		}
+	}
+}
+
+func TestLocaleContext(t *testing.T) {
+	if s := request.LocaleFromContext(context.Background()); s != "" {
+		t.Errorf("unexpected locale: %s", s)
+	}
+
+	ctx := request.NewLocaleContext(context.Background(), request.LocaleEN)
+	if s := request.LocaleFromContext(ctx); s != request.LocaleEN {
+		t.Errorf("unexpected locale: %s", s)
+	}
+}
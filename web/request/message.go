@@ -0,0 +1,126 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	i18nValidator "github.com/go-playground/validator/v10"
+)
+
+var (
+	// Placeholders of message, named placeholders are aliases of
+	// numbered placeholders.
+	//
+	//   {0}, {field} - label of field
+	//   {1}, {param} - param of tag
+	//   {2}, {value} - value of field
+	//   {3}, {other} - label of other field in cross-field tags
+	messagePlaceholders = [][2]string{
+		{"{0}", "{field}"},
+		{"{1}", "{param}"},
+		{"{2}", "{value}"},
+		{"{3}", "{other}"},
+	}
+)
+
+// RegisterWithMessages
+// custom tag for validation with messages keyed by locale.
+//
+// Locales without message use message of default locale, or
+// english if not defined.
+//
+//   v.RegisterWithMessages("gte_len", map[string]string{
+//       request.LocaleEN: "{field} must be at least {param} characters",
+//       request.LocaleZH: "{field}长度不能少于{param}个字符",
+//   }, check)
+func (o *Validator) RegisterWithMessages(tag string, messages map[string]string, check func(f i18nValidator.FieldLevel) bool) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	if err := o.valid.RegisterValidation(tag, check); err != nil {
+		return err
+	}
+
+	return o.RegisterMessages(tag, messages)
+}
+
+// RegisterMessages
+// messages of key keyed by locale, key is tag of field tags or
+// key reported by struct-level rules.
+//
+// Messages of key registered before are replaced.
+func (o *Validator) RegisterMessages(key string, messages map[string]string) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	o.messages[key] = make(map[string]string)
+	for locale, message := range messages {
+		o.messages[key][locale] = message
+	}
+	return nil
+}
+
+// Message
+// return message of tag in locale, message of default locale,
+// english or the first locale in order is used if not found.
+// The second value is false if no message registered for tag.
+func (o *Validator) message(tag, locale string) (string, bool) {
+	messages, ok := o.messages[tag]
+	if !ok {
+		return "", false
+	}
+	for _, key := range []string{locale, "", o.locale, LocaleEN} {
+		if message, ok := messages[key]; ok {
+			return message, true
+		}
+	}
+
+	// Message of the first locale in order.
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		return messages[keys[0]], true
+	}
+	return "", false
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Format message
+// replace placeholders with field, param, value and other.
+func formatMessage(message, field, param string, value interface{}, other string) string {
+	args := []string{field, param, fmt.Sprint(value), other}
+	pairs := make([]string, 0, len(messagePlaceholders)*4)
+	for i, placeholders := range messagePlaceholders {
+		for _, placeholder := range placeholders {
+			pairs = append(pairs, placeholder, args[i])
+		}
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// Other label
+// return label of field named by param of cross-field tag, it
+// is sibling of field in namespace.
+//
+//   otherLabel(t, "User.Password", "Confirm") // return label of User.Confirm
+func otherLabel(t reflect.Type, namespace, param string) string {
+	if i := strings.LastIndex(namespace, "."); i > 0 && param != "" {
+		if f, ok := structField(t, namespace[:i+1]+param); ok {
+			if label := f.Tag.Get("label"); label != "" {
+				return label
+			}
+		}
+	}
+	return param
+}
@@ -0,0 +1,45 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+func TestValidator_RegisterWithMessages(t *testing.T) {
+	type req struct {
+		Count int `json:"count" label:"Count" validate:"even"`
+	}
+
+	v, err := request.NewValidator(
+		request.WithLocale(request.LocaleEN),
+		request.WithRule("even", map[string]string{
+			request.LocaleZHHant: "{field}必須是偶數",
+			request.LocaleZH:     "{field}必须是偶数",
+		}, even),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		locale, expected string
+	}{
+		{request.LocaleZH, "Count必须是偶数"},
+		{request.LocaleZHHant, "Count必須是偶數"},
+
+		// Neither locale, default locale nor english registered,
+		// message of the first locale in order is used.
+		{request.LocaleEN, "Count必须是偶数"},
+	} {
+		for i := 0; i < 10; i++ {
+			errs, ok := v.StructLocale(&req{Count: 1}, c.locale).(request.FieldErrors)
+			if !ok || len(errs) != 1 || errs[0].Message != c.expected {
+				t.Fatalf("%s: unexpected failures: %v", c.locale, errs)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	regexHtmlTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+type (
+	// Modifier
+	// return modified string value, used by mod tag.
+	Modifier func(s string) string
+)
+
+// Modify
+// apply default and mod tags on fields of struct, nested
+// structs, slices and maps are walked.
+//
+//   type Req struct {
+//       Name  string `json:"name" mod:"trim,lower"`
+//       Limit int    `json:"limit" default:"10"`
+//   }
+//
+// Default is applied if field is zero value, then modifiers of
+// mod tag are applied in order on string values.
+func (o *Validator) Modify(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+	return o.modify(rv.Elem(), true)
+}
+
+// RegisterModifier
+// custom modifier for mod tag, built-in modifier is replaced if
+// registered already.
+//
+//   v.RegisterModifier("digits", func(s string) string {
+//       return strings.Map(func(r rune) rune {
+//           if unicode.IsDigit(r) {
+//               return r
+//           }
+//           return -1
+//       }, s)
+//   })
+func (o *Validator) RegisterModifier(name string, modifier Modifier) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	o.modifiers[name] = modifier
+	return nil
+}
+
+func (o *Validator) initModifiers() {
+	o.modifiers = map[string]Modifier{
+		"fullwidth_to_halfwidth": modifyHalfwidth,
+		"lower":                  strings.ToLower,
+		"ltrim":                  func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) },
+		"rtrim":                  func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) },
+		"strip_html":             func(s string) string { return regexHtmlTag.ReplaceAllString(s, "") },
+		"trim":                   strings.TrimSpace,
+		"upper":                  strings.ToUpper,
+	}
+}
+
+// Modify
+// walk value and apply tags on struct fields, default tag is
+// ignored if defaults is false.
+func (o *Validator) modify(rv reflect.Value, defaults bool) error {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			return o.modify(rv.Elem(), defaults)
+		}
+	case reflect.Struct:
+		if rv.Type() == typeTime {
+			return nil
+		}
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			if f := rt.Field(i); f.PkgPath == "" || f.Anonymous {
+				if err := o.modifyField(rv.Field(i), f, defaults); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := o.modify(rv.Index(i), defaults); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			// Map value is not addressable.
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			elem.Set(rv.MapIndex(key))
+			if err := o.modify(elem, defaults); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, elem)
+		}
+	}
+	return nil
+}
+
+func (o *Validator) modifyField(fv reflect.Value, f reflect.StructField, defaults bool) error {
+	if !fv.CanSet() {
+		return nil
+	}
+
+	// Default value.
+	if def, ok := f.Tag.Lookup("default"); ok && defaults && fv.IsZero() {
+		if err := convertValue(fv, []string{def}, f.Tag.Get("layout")); err != nil {
+			return fmt.Errorf("default of field '%s': %v", f.Name, err)
+		}
+	}
+
+	// Modifiers.
+	if tag := f.Tag.Get("mod"); tag != "" {
+		list := make([]Modifier, 0)
+		for _, name := range strings.Split(tag, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			modifier, ok := o.modifiers[name]
+			if !ok {
+				return fmt.Errorf("modifier '%s' of field '%s' not registered", name, f.Name)
+			}
+			list = append(list, modifier)
+		}
+		modifyString(fv, list)
+	}
+
+	return o.modify(fv, defaults)
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Modify halfwidth
+// convert fullwidth characters into halfwidth.
+//
+//   "ＡＢＣ１２３　" => "ABC123 "
+func modifyHalfwidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == 0x3000:
+			return ' '
+		case r >= 0xFF01 && r <= 0xFF5E:
+			return r - 0xFEE0
+		}
+		return r
+	}, s)
+}
+
+// Modify string
+// apply modifiers on string value, elements of slice and values
+// of map.
+func modifyString(rv reflect.Value, list []Modifier) {
+	switch rv.Kind() {
+	case reflect.String:
+		s := rv.String()
+		for _, modifier := range list {
+			s = modifier(s)
+		}
+		rv.SetString(s)
+	case reflect.Ptr:
+		if !rv.IsNil() {
+			modifyString(rv.Elem(), list)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			modifyString(rv.Index(i), list)
+		}
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			elem.Set(rv.MapIndex(key))
+			modifyString(elem, list)
+			rv.SetMapIndex(key, elem)
+		}
+	}
+}
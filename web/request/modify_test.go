@@ -0,0 +1,126 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	modifyAddress struct {
+		City string `json:"city" mod:"trim,upper"`
+		Zip  string `json:"zip" default:"000000"`
+	}
+
+	modifyReq struct {
+		Name      string                    `json:"name" mod:"trim,lower"`
+		Left      string                    `json:"left" mod:"ltrim"`
+		Right     string                    `json:"right" mod:"rtrim"`
+		Bio       string                    `json:"bio" mod:"strip_html,trim"`
+		Code      string                    `json:"code" mod:"fullwidth_to_halfwidth"`
+		Nick      *string                   `json:"nick" mod:"trim"`
+		Tags      []string                  `json:"tags" mod:"trim,lower"`
+		Labels    map[string]string         `json:"labels" mod:"upper"`
+		Limit     int                       `json:"limit" default:"10"`
+		Timeout   time.Duration             `json:"timeout" default:"3s"`
+		Enabled   *bool                     `json:"enabled" default:"true"`
+		Address   modifyAddress             `json:"address"`
+		Addresses []*modifyAddress          `json:"addresses"`
+		Regions   map[string]modifyAddress  `json:"regions"`
+		Others    map[string]*modifyAddress `json:"others"`
+	}
+)
+
+func TestValidator_Modify(t *testing.T) {
+	nick := "  Nick  "
+	req := &modifyReq{
+		Name:      "  Alice ",
+		Left:      "  a  ",
+		Right:     "  a  ",
+		Bio:       " <b>Hello</b> <i>world</i> ",
+		Code:      "ＡＢＣ１２３　",
+		Nick:      &nick,
+		Tags:      []string{" A ", "B "},
+		Labels:    map[string]string{"k": "v"},
+		Limit:     20,
+		Address:   modifyAddress{City: " hangzhou "},
+		Addresses: []*modifyAddress{{City: " shanghai ", Zip: "200000"}, nil},
+		Regions:   map[string]modifyAddress{"east": {City: " ningbo "}},
+		Others:    map[string]*modifyAddress{"west": {City: " chengdu "}},
+	}
+	if err := request.Validate.Modify(req); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name            string
+		value, expected interface{}
+	}{
+		{"trim,lower", req.Name, "alice"},
+		{"ltrim", req.Left, "a  "},
+		{"rtrim", req.Right, "  a"},
+		{"strip_html", req.Bio, "Hello world"},
+		{"fullwidth_to_halfwidth", req.Code, "ABC123 "},
+		{"pointer", *req.Nick, "Nick"},
+		{"slice", req.Tags, []string{"a", "b"}},
+		{"map", req.Labels, map[string]string{"k": "V"}},
+		{"default not applied on non-zero", req.Limit, 20},
+		{"default duration", req.Timeout, time.Second * 3},
+		{"default pointer", *req.Enabled, true},
+		{"nested struct", req.Address, modifyAddress{City: "HANGZHOU", Zip: "000000"}},
+		{"slice of struct pointers", *req.Addresses[0], modifyAddress{City: "SHANGHAI", Zip: "200000"}},
+		{"map of structs", req.Regions["east"], modifyAddress{City: "NINGBO", Zip: "000000"}},
+		{"map of struct pointers", *req.Others["west"], modifyAddress{City: "CHENGDU", Zip: "000000"}},
+	} {
+		if !reflect.DeepEqual(c.value, c.expected) {
+			t.Errorf("%s: got %v, expected %v", c.name, c.value, c.expected)
+		}
+	}
+}
+
+func TestValidator_Modify_Custom(t *testing.T) {
+	digits := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, s)
+	}
+
+	v, err := request.NewValidator(request.WithModifier("digits", digits))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &struct {
+		Mobile string `json:"mobile" mod:"digits" validate:"len=11"`
+	}{Mobile: "138-0013-8000"}
+	if err = v.Struct(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Mobile != "13800138000" {
+		t.Errorf("unexpected mobile: %s", req.Mobile)
+	}
+
+	// Modifier not registered on default validator.
+	if err = request.Validate.Modify(req); err == nil {
+		t.Errorf("expected error of unregistered modifier")
+	}
+}
+
+func TestValidator_Modify_InvalidDefault(t *testing.T) {
+	req := &struct {
+		Limit int `json:"limit" default:"ten"`
+	}{}
+	if err := request.Validate.Modify(req); err == nil {
+		t.Errorf("expected error of invalid default")
+	}
+}
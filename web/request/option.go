@@ -0,0 +1,149 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"fmt"
+
+	"github.com/go-playground/locales"
+	i18nTranslator "github.com/go-playground/universal-translator"
+	i18nValidator "github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrFrozen = fmt.Errorf("validator is frozen")
+)
+
+type (
+	// Option
+	// configure validator built by NewValidator.
+	Option func(o *Validator) error
+)
+
+// NewValidator
+// create and return validator with independent configuration.
+//
+// Validator is frozen after built, methods changing it return
+// ErrFrozen, so it is safe for concurrent use.
+// Package variable Validate is kept as mutable default.
+//
+//   v, err := request.NewValidator(
+//       request.WithLocale(request.LocaleEN),
+//       request.WithRule("even", map[string]string{
+//           request.LocaleEN: "{field} must be even",
+//       }, even),
+//   )
+func NewValidator(opts ...Option) (*Validator, error) {
+	o := (&Validator{}).init()
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := o.trans[o.locale]; !ok {
+		return nil, fmt.Errorf("locale '%s' not registered", o.locale)
+	}
+
+	o.frozen = true
+	return o, nil
+}
+
+// WithDecoder
+// register decoder for media type.
+func WithDecoder(contentType string, decoder Decoder) Option {
+	return func(o *Validator) error {
+		return o.RegisterDecoder(contentType, decoder)
+	}
+}
+
+// WithJSONOptions
+// set options of json decoder.
+func WithJSONOptions(opts JSONOptions) Option {
+	return func(o *Validator) error {
+		return o.SetJSONOptions(opts)
+	}
+}
+
+// WithLocale
+// set default locale, locale must be registered.
+func WithLocale(locale string) Option {
+	return func(o *Validator) error {
+		if _, ok := o.trans[locale]; !ok {
+			return fmt.Errorf("locale '%s' not registered", locale)
+		}
+		o.locale = locale
+		return nil
+	}
+}
+
+// WithLocales
+// keep specified locales only, all built-in locales are
+// registered if not given. Default locale is changed to the
+// first one if it is removed.
+//
+//   request.WithLocales(request.LocaleEN, request.LocaleZH)
+func WithLocales(list ...string) Option {
+	return func(o *Validator) error {
+		keep := make(map[string]bool)
+		for _, locale := range list {
+			if _, ok := o.trans[locale]; !ok {
+				return fmt.Errorf("locale '%s' not registered", locale)
+			}
+			keep[locale] = true
+		}
+		if len(keep) == 0 {
+			return fmt.Errorf("no locale specified")
+		}
+
+		for locale := range o.trans {
+			if !keep[locale] {
+				delete(o.trans, locale)
+			}
+		}
+		if !keep[o.locale] {
+			o.locale = list[0]
+		}
+		return nil
+	}
+}
+
+// WithMessages
+// register messages of key keyed by locale.
+func WithMessages(key string, messages map[string]string) Option {
+	return func(o *Validator) error {
+		return o.RegisterMessages(key, messages)
+	}
+}
+
+// WithModifier
+// register modifier for mod tag.
+func WithModifier(name string, modifier Modifier) Option {
+	return func(o *Validator) error {
+		return o.RegisterModifier(name, modifier)
+	}
+}
+
+// WithRule
+// register tag for validation with messages keyed by locale.
+func WithRule(tag string, messages map[string]string, check func(f i18nValidator.FieldLevel) bool) Option {
+	return func(o *Validator) error {
+		return o.RegisterWithMessages(tag, messages, check)
+	}
+}
+
+// WithStruct
+// register struct-level rule for type of v.
+func WithStruct(v interface{}, fn func(sl *StructLevel)) Option {
+	return func(o *Validator) error {
+		return o.RegisterStruct(v, fn)
+	}
+}
+
+// WithTranslator
+// register translator of locale and use it as default locale.
+func WithTranslator(register func(*i18nValidator.Validate, i18nTranslator.Translator) error, fallback locales.Translator, supports ...locales.Translator) Option {
+	return func(o *Validator) error {
+		return o.With(register, fallback, supports...)
+	}
+}
@@ -0,0 +1,136 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"sync"
+	"testing"
+
+	i18nValidator "github.com/go-playground/validator/v10"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type user struct {
+	Name string `json:"name" label:"Name" validate:"required"`
+	Age  int    `json:"age" label:"Age" validate:"even"`
+}
+
+func even(f i18nValidator.FieldLevel) bool { return f.Field().Int()%2 == 0 }
+
+func TestNewValidator(t *testing.T) {
+	v1, err := request.NewValidator(
+		request.WithLocales(request.LocaleEN),
+		request.WithRule("even", map[string]string{request.LocaleEN: "{field} must be even"}, even),
+	)
+	if err != nil {
+		t.Fatalf("v1: %v", err)
+	}
+	v2, err := request.NewValidator(
+		request.WithLocale(request.LocaleZH),
+		request.WithRule("even", map[string]string{request.LocaleZH: "{field}必须是偶数"}, even),
+	)
+	if err != nil {
+		t.Fatalf("v2: %v", err)
+	}
+
+	// Configurations are independent.
+	if s := v1.Locale(); s != request.LocaleEN {
+		t.Errorf("v1: unexpected locale: %s", s)
+	}
+	if list := v1.Locales(); len(list) != 1 {
+		t.Errorf("v1: unexpected locales: %v", list)
+	}
+	if s := v2.Locale(); s != request.LocaleZH {
+		t.Errorf("v2: unexpected locale: %s", s)
+	}
+
+	for _, c := range []struct {
+		v       *request.Validator
+		message string
+	}{
+		{v1, "Age must be even"},
+		{v2, "Age必须是偶数"},
+	} {
+		err = c.v.Struct(&user{Name: "Alice", Age: 1})
+		if err == nil || err.Error() != c.message {
+			t.Errorf("unexpected error: %v, expected: %s", err, c.message)
+		}
+	}
+
+	// Default validator is not changed, it panics on tag not
+	// registered.
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("default validator: expected panic of unregistered tag")
+			}
+		}()
+		_ = request.Validate.Struct(&user{Name: "Alice", Age: 1})
+	}()
+}
+
+func TestNewValidator_Frozen(t *testing.T) {
+	v, err := request.NewValidator()
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	noop := func(s string) string { return s }
+	for name, fn := range map[string]func() error{
+		"Register":             func() error { return v.Register("even", "{0} must be even", even) },
+		"RegisterWithMessages": func() error { return v.RegisterWithMessages("even", nil, even) },
+		"RegisterMessages":     func() error { return v.RegisterMessages("even", nil) },
+		"RegisterModifier":     func() error { return v.RegisterModifier("noop", noop) },
+		"RegisterDecoder":      func() error { return v.RegisterDecoder("application/yaml", nil) },
+		"RegisterStruct":       func() error { return v.RegisterStruct(user{}, func(*request.StructLevel) {}) },
+		"SetJSONOptions":       func() error { return v.SetJSONOptions(request.JSONOptions{}) },
+		"Translate":            func() error { return v.Translate("required", request.LocaleEN, "{0}") },
+		"WithEN":               v.WithEN,
+		"WithZH":               v.WithZH,
+		"WithZHHant":           v.WithZHHant,
+	} {
+		if err = fn(); err != request.ErrFrozen {
+			t.Errorf("%s: expected ErrFrozen, got %v", name, err)
+		}
+	}
+
+	// Frozen validator is not changed.
+	if _, ok := v.Decoder("application/yaml"); ok {
+		t.Errorf("decoder registered on frozen validator")
+	}
+	if err = v.Modify(&struct {
+		Name string `mod:"noop"`
+	}{}); err == nil {
+		t.Errorf("modifier registered on frozen validator")
+	}
+}
+
+func TestNewValidator_Invalid(t *testing.T) {
+	if _, err := request.NewValidator(request.WithLocale("fr")); err == nil {
+		t.Errorf("expected error of unregistered locale")
+	}
+	if _, err := request.NewValidator(request.WithLocales()); err == nil {
+		t.Errorf("expected error of empty locales")
+	}
+}
+
+func TestNewValidator_Concurrent(t *testing.T) {
+	v, err := request.NewValidator(request.WithRule("even", nil, even))
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, locale := range v.Locales() {
+				_ = v.StructLocale(&user{Age: i}, locale)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,362 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fuyibing/util/v8/web/response"
+)
+
+const (
+	DefaultPageLimit    = 10
+	DefaultPageMaxLimit = 100
+
+	FilterBool   = "bool"
+	FilterFloat  = "float"
+	FilterInt    = "int"
+	FilterString = "string"
+	FilterTime   = "time"
+
+	OpEq   = "eq"
+	OpGt   = "gt"
+	OpGte  = "gte"
+	OpIn   = "in"
+	OpLike = "like"
+	OpLt   = "lt"
+	OpLte  = "lte"
+	OpNe   = "ne"
+	OpNin  = "nin"
+
+	// Tags of page query failures.
+	TagPageFilter = "page_filter"
+	TagPageMax    = "page_max"
+	TagPageMin    = "page_min"
+	TagPageSort   = "page_sort"
+
+	maxInt = int(^uint(0) >> 1)
+)
+
+var (
+	// Messages of page query failures, replace by Translate with
+	// TagPageFilter, TagPageMax, TagPageMin and TagPageSort.
+	pageMessages = map[string]map[string]string{
+		TagPageFilter: {
+			LocaleEN:     "{field} is not a supported filter",
+			LocaleZH:     "{field}不是支持的筛选条件",
+			LocaleZHHant: "{field}不是支援的篩選條件",
+		},
+		TagPageMax: {
+			LocaleEN:     "{field} must be {param} or less",
+			LocaleZH:     "{field}必须小于或等于{param}",
+			LocaleZHHant: "{field}必須小於或等於{param}",
+		},
+		TagPageMin: {
+			LocaleEN:     "{field} must be {param} or greater",
+			LocaleZH:     "{field}必须大于或等于{param}",
+			LocaleZHHant: "{field}必須大於或等於{param}",
+		},
+		TagPageSort: {
+			LocaleEN:     "{field} can not sort by {value}",
+			LocaleZH:     "{field}不支持按{value}排序",
+			LocaleZHHant: "{field}不支援按{value}排序",
+		},
+	}
+
+	// Value types of filter types.
+	filterTypes = map[string]reflect.Type{
+		FilterBool:   reflect.TypeOf(false),
+		FilterFloat:  reflect.TypeOf(float64(0)),
+		FilterInt:    reflect.TypeOf(int64(0)),
+		FilterString: reflect.TypeOf(""),
+		FilterTime:   typeTime,
+	}
+
+	// Time layouts of time filter.
+	filterTimeLayouts = []string{DefaultTimeLayout, "2006-01-02 15:04:05", "2006-01-02"}
+
+	filterOps = map[string]bool{
+		OpEq: true, OpGt: true, OpGte: true, OpIn: true, OpLike: true,
+		OpLt: true, OpLte: true, OpNe: true, OpNin: true,
+	}
+)
+
+type (
+	// PageOptions
+	// constraints of page query.
+	//
+	//   request.PageOptions{
+	//       MaxLimit: 50,
+	//       Sorts:    []string{"id", "created"},
+	//       Filters:  map[string]string{"status": request.FilterInt, "created": request.FilterTime},
+	//   }
+	PageOptions struct {
+		// DefaultLimit
+		// used if limit not given, default DefaultPageLimit.
+		DefaultLimit int
+
+		// MaxLimit
+		// max value of limit, default DefaultPageMaxLimit.
+		MaxLimit int
+
+		// Sorts
+		// whitelist of sort fields.
+		Sorts []string
+
+		// Filters
+		// whitelist of filter fields with filter types.
+		Filters map[string]string
+	}
+
+	// PageQuery
+	// page, limit, sort and filters bound from query string.
+	//
+	//   ?page=2&limit=20&sort=-created,id&filter[status]=1&filter[created][gte]=2023-01-01
+	PageQuery struct {
+		Page    int
+		Limit   int
+		Sorts   []Sort
+		Filters []Filter
+	}
+
+	// Sort
+	// field of sort, prefix - in query means descending.
+	Sort struct {
+		Field string
+		Desc  bool
+	}
+
+	// Filter
+	// typed condition of field. Value is slice if operator is
+	// in or nin.
+	Filter struct {
+		Field string
+		Op    string
+		Value interface{}
+	}
+)
+
+// Page
+// bind page query from request with default validator.
+func Page(r *http.Request, opts PageOptions) (*PageQuery, error) { return Validate.Page(r, opts) }
+
+// Page
+// bind page query from request, failures are translated in
+// locale negotiated by Accept-Language header.
+//
+//   q, err := request.Validate.Page(r, opts)
+//   if err != nil {
+//       return err.(request.FieldErrors).Result(code)
+//   }
+//   list, total := repo.Find(q.Filters, q.Sorts, q.Offset(), q.Limit)
+//   return q.Paging(list, total)
+func (o *Validator) Page(r *http.Request, opts PageOptions) (*PageQuery, error) {
+	locale := LocaleFromContext(r.Context())
+	if locale == "" {
+		locale = o.Negotiate(r.Header.Get("Accept-Language"))
+	}
+	return o.PageValues(r.URL.Query(), opts, locale)
+}
+
+// PageValues
+// bind page query from query values, failures are translated
+// in specified locale.
+//
+// Return FieldErrors if page or limit is invalid or less than 1,
+// limit exceeds max limit, offset of page overflows, sort field
+// or filter not allowed.
+func (o *Validator) PageValues(values url.Values, opts PageOptions, locale string) (*PageQuery, error) {
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = DefaultPageLimit
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = DefaultPageMaxLimit
+	}
+
+	var (
+		errs = make(FieldErrors, 0)
+		q    = &PageQuery{Page: 1, Limit: opts.DefaultLimit, Sorts: make([]Sort, 0), Filters: make([]Filter, 0)}
+	)
+
+	report := func(field, tag, param, value string) {
+		message, _ := o.message(tag, locale)
+		errs = append(errs, &FieldError{
+			Namespace: "PageQuery." + field, Field: field, Label: field,
+			Tag: tag, Param: param, Value: value,
+			Message: formatMessage(message, field, param, value, ""),
+		})
+	}
+
+	// Page and limit.
+	if s := values.Get("page"); s != "" {
+		if n, err := strconv.Atoi(s); err != nil {
+			report("page", TagType, "int", s)
+		} else if n < 1 {
+			report("page", TagPageMin, "1", s)
+		} else {
+			q.Page = n
+		}
+	}
+	if s := values.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err != nil {
+			report("limit", TagType, "int", s)
+		} else if n < 1 {
+			report("limit", TagPageMin, "1", s)
+		} else if n > opts.MaxLimit {
+			report("limit", TagPageMax, strconv.Itoa(opts.MaxLimit), s)
+		} else {
+			q.Limit = n
+		}
+	}
+
+	// Offset of page overflows.
+	if q.Page-1 > maxInt/q.Limit {
+		report("page", TagPageMax, strconv.Itoa(maxInt/q.Limit+1), values.Get("page"))
+	}
+
+	// Sort fields.
+	allowed := make(map[string]bool)
+	for _, field := range opts.Sorts {
+		allowed[field] = true
+	}
+	for _, s := range values["sort"] {
+		for _, field := range strings.Split(s, ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			sf := Sort{Field: strings.TrimLeft(field, "+-"), Desc: strings.HasPrefix(field, "-")}
+			if !allowed[sf.Field] {
+				report("sort", TagPageSort, "", sf.Field)
+				continue
+			}
+			q.Sorts = append(q.Sorts, sf)
+		}
+	}
+
+	// Filters.
+	keys := make([]string, 0)
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field, op, ok := parseFilterKey(key)
+		kind, known := opts.Filters[field]
+		if !ok || !known || !filterOps[op] {
+			report(key, TagPageFilter, "", strings.Join(values[key], ","))
+			continue
+		}
+
+		f, err := newFilter(field, op, kind, values[key])
+		if err != nil {
+			report(key, TagType, kind, strings.Join(values[key], ","))
+			continue
+		}
+		q.Filters = append(q.Filters, f)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return q, nil
+}
+
+// /////////////////////////////////////////////////////////////
+// PageQuery methods.
+// /////////////////////////////////////////////////////////////
+
+// Filter
+// return filters of field.
+func (o *PageQuery) Filter(field string) []Filter {
+	list := make([]Filter, 0)
+	for _, f := range o.Filters {
+		if f.Field == field {
+			list = append(list, f)
+		}
+	}
+	return list
+}
+
+// Offset
+// return offset of first item in page.
+func (o *PageQuery) Offset() int { return (o.Page - 1) * o.Limit }
+
+// Paging
+// return paging result of items in page.
+func (o *PageQuery) Paging(v interface{}, total int64) *response.Result {
+	return response.With.Paging(v, total, o.Limit, o.Page)
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// New filter
+// return filter with value converted into type of kind.
+func newFilter(field, op, kind string, values []string) (Filter, error) {
+	t, ok := filterTypes[kind]
+	if !ok {
+		return Filter{}, fmt.Errorf("unknown filter type '%s'", kind)
+	}
+
+	parse := func(s string) (interface{}, error) {
+		v := reflect.New(t).Elem()
+		if t == typeTime {
+			for _, layout := range filterTimeLayouts {
+				if tm, err := time.Parse(layout, s); err == nil {
+					return tm, nil
+				}
+			}
+		}
+		if err := convertValue(v, []string{s}, ""); err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+
+	f := Filter{Field: field, Op: op}
+	if op == OpIn || op == OpNin {
+		list := make([]interface{}, 0)
+		for _, value := range values {
+			for _, s := range strings.Split(value, ",") {
+				v, err := parse(strings.TrimSpace(s))
+				if err != nil {
+					return f, err
+				}
+				list = append(list, v)
+			}
+		}
+		f.Value = list
+		return f, nil
+	}
+
+	v, err := parse(values[0])
+	f.Value = v
+	return f, err
+}
+
+// Parse filter key
+// return field and operator of filter key.
+//
+//   "filter[status]"        => "status", "eq"
+//   "filter[created][gte]"  => "created", "gte"
+func parseFilterKey(key string) (field, op string, ok bool) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]"), "][")
+	switch len(parts) {
+	case 1:
+		return parts[0], OpEq, parts[0] != ""
+	case 2:
+		return parts[0], parts[1], parts[0] != ""
+	}
+	return "", "", false
+}
@@ -0,0 +1,157 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+var pageOptions = request.PageOptions{
+	MaxLimit: 50,
+	Sorts:    []string{"id", "created"},
+	Filters: map[string]string{
+		"status":  request.FilterInt,
+		"name":    request.FilterString,
+		"enabled": request.FilterBool,
+		"score":   request.FilterFloat,
+		"created": request.FilterTime,
+	},
+}
+
+func TestValidator_PageValues(t *testing.T) {
+	created := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		query    string
+		expected request.PageQuery
+	}{
+		{"", request.PageQuery{Page: 1, Limit: 10}},
+		{"page=3&limit=50", request.PageQuery{Page: 3, Limit: 50}},
+		{"sort=-created,id&sort=+id", request.PageQuery{Page: 1, Limit: 10, Sorts: []request.Sort{{"created", true}, {"id", false}, {"id", false}}}},
+		{"filter[status]=1", request.PageQuery{Page: 1, Limit: 10, Filters: []request.Filter{{"status", request.OpEq, int64(1)}}}},
+		{"filter[created][gte]=2023-03-01&filter[name][like]=ali", request.PageQuery{Page: 1, Limit: 10, Filters: []request.Filter{
+			{"created", request.OpGte, created}, {"name", request.OpLike, "ali"},
+		}}},
+		{"filter[enabled][ne]=false&filter[score][lt]=1.5", request.PageQuery{Page: 1, Limit: 10, Filters: []request.Filter{
+			{"enabled", request.OpNe, false}, {"score", request.OpLt, 1.5},
+		}}},
+		{"filter[status][in]=1,2&filter[status][in]=3", request.PageQuery{Page: 1, Limit: 10, Filters: []request.Filter{
+			{"status", request.OpIn, []interface{}{int64(1), int64(2), int64(3)}},
+		}}},
+		{"filter[name][nin]=a, b", request.PageQuery{Page: 1, Limit: 10, Filters: []request.Filter{
+			{"name", request.OpNin, []interface{}{"a", "b"}},
+		}}},
+	} {
+		values, _ := url.ParseQuery(c.query)
+		q, err := request.Validate.PageValues(values, pageOptions, request.LocaleEN)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.query, err)
+			continue
+		}
+		if c.expected.Sorts == nil {
+			c.expected.Sorts = []request.Sort{}
+		}
+		if c.expected.Filters == nil {
+			c.expected.Filters = []request.Filter{}
+		}
+		if !reflect.DeepEqual(*q, c.expected) {
+			t.Errorf("%s: got %+v, expected %+v", c.query, *q, c.expected)
+		}
+	}
+}
+
+func TestValidator_PageValues_Errors(t *testing.T) {
+	for _, c := range []struct {
+		query                      string
+		field, tag, param, message string
+	}{
+		// Page and limit.
+		{"page=x", "page", request.TagType, "int", "page must be a valid int"},
+		{"page=0", "page", request.TagPageMin, "1", "page must be 1 or greater"},
+		{"page=-3", "page", request.TagPageMin, "1", "page must be 1 or greater"},
+		{"limit=0", "limit", request.TagPageMin, "1", "limit must be 1 or greater"},
+		{"limit=-1", "limit", request.TagPageMin, "1", "limit must be 1 or greater"},
+		{"limit=51", "limit", request.TagPageMax, "50", "limit must be 50 or less"},
+		{"limit=1.5", "limit", request.TagType, "int", "limit must be a valid int"},
+
+		// Sort whitelist.
+		{"sort=password", "sort", request.TagPageSort, "", "sort can not sort by password"},
+		{"sort=id,-password", "sort", request.TagPageSort, "", "sort can not sort by password"},
+
+		// Filter keys.
+		{"filter[password]=x", "filter[password]", request.TagPageFilter, "", "filter[password] is not a supported filter"},
+		{"filter[status][between]=1", "filter[status][between]", request.TagPageFilter, "", "filter[status][between] is not a supported filter"},
+		{"filter[status][gt][x]=1", "filter[status][gt][x]", request.TagPageFilter, "", "filter[status][gt][x] is not a supported filter"},
+		{"filter[]=1", "filter[]", request.TagPageFilter, "", "filter[] is not a supported filter"},
+
+		// Filter values.
+		{"filter[status]=x", "filter[status]", request.TagType, request.FilterInt, "filter[status] must be a valid int"},
+		{"filter[status][in]=1,x", "filter[status][in]", request.TagType, request.FilterInt, "filter[status][in] must be a valid int"},
+		{"filter[enabled]=yes", "filter[enabled]", request.TagType, request.FilterBool, "filter[enabled] must be a valid bool"},
+		{"filter[score][gte]=high", "filter[score][gte]", request.TagType, request.FilterFloat, "filter[score][gte] must be a valid float"},
+		{"filter[created][lt]=yesterday", "filter[created][lt]", request.TagType, request.FilterTime, "filter[created][lt] must be a valid time"},
+	} {
+		values, _ := url.ParseQuery(c.query)
+		q, err := request.Validate.PageValues(values, pageOptions, request.LocaleEN)
+		errs, ok := err.(request.FieldErrors)
+		if q != nil || !ok || len(errs) != 1 {
+			t.Errorf("%s: unexpected result: %v, %v", c.query, q, err)
+			continue
+		}
+		if e := errs[0]; e.Field != c.field || e.Tag != c.tag || e.Param != c.param || e.Message != c.message {
+			t.Errorf("%s: unexpected failure: %+v", c.query, e)
+		}
+	}
+
+	// Offset of page overflows.
+	maxInt := int(^uint(0) >> 1)
+	values, _ := url.ParseQuery(fmt.Sprintf("page=%d&limit=50", maxInt))
+	errs, ok := errorsOf(request.Validate.PageValues(values, pageOptions, request.LocaleEN))
+	if max := strconv.Itoa(maxInt/50 + 1); !ok || len(errs) != 1 || errs[0].Tag != request.TagPageMax || errs[0].Param != max {
+		t.Errorf("unexpected failures: %v", errs)
+	}
+	values, _ = url.ParseQuery(fmt.Sprintf("page=%d&limit=50", maxInt/50+1))
+	if q, err := request.Validate.PageValues(values, pageOptions, request.LocaleEN); err != nil || q.Offset() < 0 {
+		t.Errorf("unexpected result: %v, %v", q, err)
+	}
+
+	// All failures are reported.
+	values, _ = url.ParseQuery("page=-3&limit=0&sort=password&filter[password]=x")
+	if _, err := request.Validate.PageValues(values, pageOptions, request.LocaleEN); len(errorFields(t, err)) != 4 {
+		t.Errorf("unexpected failures: %v", err)
+	}
+}
+
+func errorsOf(_ *request.PageQuery, err error) (request.FieldErrors, bool) {
+	errs, ok := err.(request.FieldErrors)
+	return errs, ok
+}
+
+func TestValidator_Page(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?page=2&limit=20&filter[status]=1", nil)
+	q, err := request.Page(r, pageOptions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Offset() != 20 || len(q.Filter("status")) != 1 || len(q.Filter("name")) != 0 {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	// Failures translated in negotiated locale.
+	r = httptest.NewRequest(http.MethodGet, "/users?page=-3", nil)
+	r.Header.Set("Accept-Language", "zh-TW")
+	_, err = request.Page(r, pageOptions)
+	if errs, ok := err.(request.FieldErrors); !ok || len(errs) != 1 || errs[0].Message != "page必須大於或等於1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
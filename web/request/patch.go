@@ -0,0 +1,99 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// FieldMask
+	// json paths of fields present in body, paths are named as
+	// json path of field error, keys not matched any field are
+	// not included.
+	//
+	//   {"Name": "Alice", "address": {"city": "Hangzhou"}, "tags": ["a"]}
+	//   // paths: address, address.city, name, tags, tags[0]
+	FieldMask map[string]bool
+)
+
+// Patch
+// unmarshal body for partial update, return paths present in
+// body and validate result of present fields only.
+//
+// Default tags are not applied, fields omitted in body keep
+// zero values.
+//
+//   mask, err := v.Patch(req, body)
+//   if mask.Has("name") {
+//       columns = append(columns, "name")
+//   }
+func (o *Validator) Patch(v interface{}, body []byte) (FieldMask, error) {
+	return o.PatchLocale(v, body, o.locale)
+}
+
+// PatchLocale
+// unmarshal body for partial update, return paths present in
+// body and validate result translated in specified locale.
+func (o *Validator) PatchLocale(v interface{}, body []byte, locale string) (FieldMask, error) {
+	if err := o.decode(v, ContentTypeJson, bytes.NewReader(body)); err != nil {
+		return nil, err
+	}
+
+	// Paths of struct fields decoded, keys are matched and
+	// embedded structs are flattened as encoding/json.
+	mask := make(FieldMask)
+	if err := scanJson(body, JSONOptions{}, reflect.TypeOf(v), func(path string) { mask[path] = true }); err != nil {
+		return nil, err
+	}
+
+	// Modify without defaults.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		if err := o.modify(rv.Elem(), false); err != nil {
+			return mask, err
+		}
+	}
+
+	t := reflect.TypeOf(v)
+	return mask, o.failures(v, locale, o.valid.StructFiltered(v, func(ns []byte) bool {
+		p := jsonPath(t, string(ns))
+		return p != "" && !mask.Has(p)
+	}))
+}
+
+// /////////////////////////////////////////////////////////////
+// FieldMask methods.
+// /////////////////////////////////////////////////////////////
+
+// Has
+// return true if json path present in body.
+func (o FieldMask) Has(path string) bool { return o[path] }
+
+// Paths
+// return sorted paths present in body.
+func (o FieldMask) Paths() []string {
+	list := make([]string, 0, len(o))
+	for path := range o {
+		list = append(list, path)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Top
+// return sorted top-level keys present in body, used as columns
+// of UPDATE statement.
+func (o FieldMask) Top() []string {
+	list := make([]string, 0)
+	for path := range o {
+		if !strings.ContainsAny(path, ".[") {
+			list = append(list, path)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,83 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	patchBase struct {
+		Name string `json:"name" validate:"max=3"`
+	}
+
+	patchAddress struct {
+		City string `json:"city" mod:"trim" validate:"required"`
+		Zip  string `json:"zip" validate:"required"`
+	}
+
+	patchReq struct {
+		patchBase
+		Age       int                     `json:"age" validate:"max=10"`
+		Email     string                  `json:"email" validate:"required,email"`
+		Address   *patchAddress           `json:"address"`
+		Addresses []patchAddress          `json:"addresses" validate:"dive"`
+		Regions   map[string]patchAddress `json:"regions" validate:"dive"`
+		Extra     map[string]interface{}  `json:"extra"`
+	}
+)
+
+func TestValidator_Patch(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		body     string
+		paths    []string
+		failures []string
+	}{
+		{"empty", `{}`, []string{}, nil},
+		{"valid", `{"age":8}`, []string{"age"}, nil},
+		{"case insensitive", `{"Age":99}`, []string{"age"}, []string{"age"}},
+		{"embedded", `{"name":"toolong"}`, []string{"name"}, []string{"name"}},
+		{"embedded case insensitive", `{"NAME":"toolong"}`, []string{"name"}, []string{"name"}},
+		{"nested", `{"address":{"CITY":" "}}`, []string{"address", "address.city"}, []string{"address.city"}},
+		{"slice", `{"addresses":[{"city":"a","zip":"1"},{"city":"b"}]}`,
+			[]string{"addresses", "addresses[0]", "addresses[0].city", "addresses[0].zip", "addresses[1]", "addresses[1].city"}, nil},
+		{"map", `{"regions":{"east":{"city":" ","zip":"1"}}}`,
+			[]string{"regions", "regions[east]", "regions[east].city", "regions[east].zip"}, []string{"regions[east].city"}},
+		{"unknown", `{"unknown":{"age":99},"extra":{"x":{"y":1}}}`, []string{"extra", "extra[x]", "extra[x].y"}, nil},
+	} {
+		mask, err := request.Validate.PatchLocale(&patchReq{}, []byte(c.body), request.LocaleEN)
+		if paths := mask.Paths(); !reflect.DeepEqual(paths, c.paths) {
+			t.Errorf("%s: got paths %v, expected %v", c.name, paths, c.paths)
+		}
+		if fields := errorFields(t, err); !reflect.DeepEqual(fields, c.failures) {
+			t.Errorf("%s: got failures %v, expected %v", c.name, fields, c.failures)
+		}
+	}
+}
+
+func TestValidator_Patch_Mask(t *testing.T) {
+	req := &patchReq{}
+	mask, err := request.Validate.Patch(req, []byte(`{"Name":"bob","age":3,"address":{"city":" x ","zip":"1"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if top := mask.Top(); !reflect.DeepEqual(top, []string{"address", "age", "name"}) {
+		t.Errorf("unexpected top: %v", top)
+	}
+	if !mask.Has("address.city") || mask.Has("email") {
+		t.Errorf("unexpected mask: %v", mask.Paths())
+	}
+	if req.Name != "bob" || req.Address.City != "x" {
+		t.Errorf("unexpected result: %+v", req)
+	}
+
+	// Invalid body.
+	if _, err = request.Validate.Patch(req, []byte(`{"age":"x"}`)); err == nil {
+		t.Errorf("expected decode error")
+	}
+}
@@ -0,0 +1,134 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"reflect"
+	"strings"
+
+	i18nValidator "github.com/go-playground/validator/v10"
+)
+
+// StructPartial
+// return validate result of named fields only, fields are
+// relative to struct.
+//
+//   err := v.StructPartial(req, "Name", "Address.City")
+func (o *Validator) StructPartial(v interface{}, fields ...string) error {
+	return o.StructPartialLocale(v, o.locale, fields...)
+}
+
+// StructPartialLocale
+// return validate result of named fields only translated in
+// specified locale.
+func (o *Validator) StructPartialLocale(v interface{}, locale string, fields ...string) error {
+	return o.validate(v, locale, func() error { return o.valid.StructPartial(v, fields...) })
+}
+
+// StructScene
+// return validate result in scene.
+//
+// Field with scene tag is validated only if scene is listed in
+// tag, field without scene tag is always validated.
+//
+// Nested fields follow the same rule by their own scene tags,
+// and they are validated only if all parent fields in scene. So
+// fields of struct, slice or map field excluded by scene are
+// skipped, while fields of nested field without scene tag are
+// validated as long as themselves in scene.
+//
+//   type Req struct {
+//       Id   int64  `json:"id" validate:"required" scene:"update"`
+//       Name string `json:"name" validate:"required,max=32"`
+//   }
+//
+//   err := v.StructScene(req, "create") // Id is not validated
+func (o *Validator) StructScene(v interface{}, scene string) error {
+	return o.StructSceneLocale(v, scene, o.locale)
+}
+
+// StructSceneLocale
+// return validate result in scene translated in specified
+// locale.
+func (o *Validator) StructSceneLocale(v interface{}, scene, locale string) error {
+	t := reflect.TypeOf(v)
+	return o.validate(v, locale, func() error {
+		return o.valid.StructFiltered(v, func(ns []byte) bool {
+			if f, ok := structField(t, string(ns)); ok {
+				return !inScene(f, scene)
+			}
+			return false
+		})
+	})
+}
+
+// Validate
+// apply modifiers then run validation, failures are translated
+// in locale.
+func (o *Validator) validate(v interface{}, locale string, run func() error) error {
+	if err := o.Modify(v); err != nil {
+		return err
+	}
+	return o.failures(v, locale, run())
+}
+
+// Failures
+// return FieldErrors translated in locale if err is validation
+// errors.
+func (o *Validator) failures(v interface{}, locale string, err error) error {
+	if err != nil {
+		if errs, ok := err.(i18nValidator.ValidationErrors); ok {
+			return newFieldErrors(v, errs, o.translate(v, locale))
+		}
+	}
+	return err
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// In scene
+// return true if field has no scene tag or scene listed in tag.
+func inScene(f reflect.StructField, scene string) bool {
+	tag, ok := f.Tag.Lookup("scene")
+	if !ok {
+		return true
+	}
+	for _, s := range strings.Split(tag, ",") {
+		if strings.TrimSpace(s) == scene {
+			return true
+		}
+	}
+	return false
+}
+
+// Struct field
+// return struct field of struct namespace.
+//
+//   structField(t, "User.Addresses[0].City")
+func structField(t reflect.Type, namespace string) (f reflect.StructField, ok bool) {
+	segments := strings.Split(namespace, ".")
+	if len(segments) < 2 {
+		return
+	}
+
+	for _, segment := range segments[1:] {
+		if i := strings.Index(segment, "["); i > 0 {
+			segment = segment[:i]
+		}
+		if t = indirectType(t); t.Kind() != reflect.Struct {
+			return reflect.StructField{}, false
+		}
+		if f, ok = t.FieldByName(segment); !ok {
+			return
+		}
+
+		// Element type of slice, array or map.
+		for t = indirectType(f.Type); t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map; {
+			t = indirectType(t.Elem())
+		}
+	}
+	return
+}
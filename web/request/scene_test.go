@@ -0,0 +1,111 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	sceneAddress struct {
+		City string `json:"city" mod:"trim" validate:"required"`
+		Zip  string `json:"zip" validate:"required,len=6" scene:"update"`
+	}
+
+	sceneItem struct {
+		Id  int64  `json:"id" validate:"required" scene:"update"`
+		Sku string `json:"sku" validate:"required"`
+	}
+
+	sceneReq struct {
+		Id      int64         `json:"id" validate:"required" scene:"update"`
+		Name    string        `json:"name" validate:"required,max=8" scene:"create, update"`
+		Address sceneAddress  `json:"address"`
+		Items   []sceneItem   `json:"items" validate:"dive"`
+		Billing *sceneAddress `json:"billing" validate:"required" scene:"update"`
+	}
+)
+
+func TestValidator_StructScene(t *testing.T) {
+	for _, c := range []struct {
+		scene    string
+		expected []string
+	}{
+		// Address and Items have no scene tag, their fields
+		// without scene tag are validated in all scenes.
+		{"create", []string{"name", "address.city", "items[0].sku"}},
+		{"update", []string{"id", "name", "address.city", "address.zip", "items[0].id", "items[0].sku", "billing"}},
+		{"other", []string{"address.city", "items[0].sku"}},
+	} {
+		err := request.Validate.StructSceneLocale(&sceneReq{Items: []sceneItem{{}}}, c.scene, request.LocaleEN)
+		if fields := errorFields(t, err); !reflect.DeepEqual(fields, c.expected) {
+			t.Errorf("%s: got %v, expected %v", c.scene, fields, c.expected)
+		}
+	}
+
+	// Modifiers applied before validation.
+	req := &sceneReq{Name: "alice", Address: sceneAddress{City: " "}, Items: []sceneItem{{Sku: "a"}}}
+	err := request.Validate.StructSceneLocale(req, "create", request.LocaleEN)
+	if fields := errorFields(t, err); !reflect.DeepEqual(fields, []string{"address.city"}) {
+		t.Errorf("unexpected failures: %v", fields)
+	}
+}
+
+func TestValidator_StructScene_Nested(t *testing.T) {
+	type req struct {
+		Name    string        `json:"name" validate:"required"`
+		Home    sceneAddress  `json:"home" scene:"update"`
+		Billing *sceneAddress `json:"billing" validate:"required" scene:"update"`
+		Items   []sceneItem   `json:"items" validate:"dive" scene:"update"`
+	}
+
+	for _, c := range []struct {
+		scene    string
+		expected []string
+	}{
+		// Fields nested in excluded fields are skipped.
+		{"create", []string{"name"}},
+		{"update", []string{"name", "home.city", "home.zip", "billing.city", "billing.zip", "items[0].id", "items[0].sku"}},
+	} {
+		err := request.Validate.StructSceneLocale(&req{Billing: &sceneAddress{}, Items: []sceneItem{{}}}, c.scene, request.LocaleEN)
+		if fields := errorFields(t, err); !reflect.DeepEqual(fields, c.expected) {
+			t.Errorf("%s: got %v, expected %v", c.scene, fields, c.expected)
+		}
+	}
+}
+
+func TestValidator_StructPartial(t *testing.T) {
+	for _, c := range []struct {
+		fields   []string
+		req      *sceneReq
+		expected []string
+	}{
+		{[]string{"Name", "Address.City"}, &sceneReq{}, []string{"name", "address.city"}},
+		{[]string{"Name", "Address.City"}, &sceneReq{Name: "too long name", Address: sceneAddress{City: "x"}}, []string{"name"}},
+		{[]string{"Address.Zip"}, &sceneReq{Address: sceneAddress{Zip: "1"}}, []string{"address.zip"}},
+		{[]string{"Id"}, &sceneReq{Id: 1}, nil},
+	} {
+		err := request.Validate.StructPartialLocale(c.req, request.LocaleEN, c.fields...)
+		if fields := errorFields(t, err); !reflect.DeepEqual(fields, c.expected) {
+			t.Errorf("%v: got %v, expected %v", c.fields, fields, c.expected)
+		}
+	}
+}
+
+func errorFields(t *testing.T, err error) (fields []string) {
+	if err == nil {
+		return
+	}
+	errs, ok := err.(request.FieldErrors)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	return
+}
@@ -0,0 +1,104 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package schema
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Run
+// command line entry of document generator, document of types
+// selected by flags in args is written to w. Types are selected
+// from registered types, all of them are selected if neither -t
+// nor -p given.
+//
+//   -f        output format, jsonschema or openapi
+//   -title    title of openapi document
+//   -version  version of openapi document
+//   -t        comma separated names of registered types
+//   -p        comma separated import paths of packages
+//
+// No binary is shipped as types must be registered by packages
+// of service. Command of service imports packages of request
+// types, which register types in init functions, then runs it.
+//
+//   import _ "example.com/user/app/requests"
+//
+//   func main() {
+//       if err := schema.Run(os.Args[1:], os.Stdout); err != nil {
+//           fmt.Fprintf(os.Stderr, "reqschema: %v\n", err)
+//           os.Exit(1)
+//       }
+//   }
+//
+//   reqschema -f openapi -t CreateUser,UpdateUser > openapi.json
+//   reqschema -f jsonschema -p example.com/user/app/requests > schema.json
+func Run(args []string, w io.Writer) error {
+	var (
+		fs       = flag.NewFlagSet("reqschema", flag.ContinueOnError)
+		format   = fs.String("f", FormatOpenAPI, "output format, jsonschema or openapi")
+		title    = fs.String("title", "api", "title of openapi document")
+		version  = fs.String("version", "1.0.0", "version of openapi document")
+		names    = fs.String("t", "", "comma separated names of registered types, all types if empty")
+		packages = fs.String("p", "", "comma separated import paths of packages, all packages if empty")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list, err := selected(split(*names), split(*packages))
+	if err != nil {
+		return err
+	}
+	return write(w, *format, *title, *version, list)
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Selected
+// return registered types named in names or declared in
+// packages, return error if name or package matched nothing.
+func selected(names, packages []string) (map[string]reflect.Type, error) {
+	all := registered()
+	if len(names) == 0 && len(packages) == 0 {
+		return all, nil
+	}
+
+	list := make(map[string]reflect.Type)
+	for _, name := range names {
+		t, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("type '%s' not registered", name)
+		}
+		list[name] = t
+	}
+	for _, pkg := range packages {
+		found := false
+		for name, t := range all {
+			if t.PkgPath() == pkg {
+				found, list[name] = true, t
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no type registered in package '%s'", pkg)
+		}
+	}
+	return list, nil
+}
+
+func split(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,82 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request"
+	"github.com/fuyibing/util/v8/web/request/schema"
+)
+
+type (
+	runItem struct {
+		Sku string `json:"sku" validate:"required"`
+	}
+)
+
+func TestRun(t *testing.T) {
+	schema.Register(user{}, "RunUser")
+	schema.Register(runItem{}, "RunItem")
+	schema.Register(request.FieldError{}, "RunFieldError")
+
+	for _, c := range []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"-f", "jsonschema", "-t", "RunUser"}, []string{"RunUser", "address"}},
+		{[]string{"-f", "jsonschema", "-t", "RunItem, RunFieldError"}, []string{"RunFieldError", "RunItem"}},
+		{[]string{"-f", "jsonschema", "-p", "github.com/fuyibing/util/v8/web/request"}, []string{"RunFieldError"}},
+		{[]string{"-f", "jsonschema", "-t", "RunItem", "-p", "github.com/fuyibing/util/v8/web/request"}, []string{"RunFieldError", "RunItem"}},
+	} {
+		buf := &bytes.Buffer{}
+		if err := schema.Run(c.args, buf); err != nil {
+			t.Errorf("%v: unexpected error: %v", c.args, err)
+			continue
+		}
+
+		doc := &schema.Schema{}
+		if err := json.Unmarshal(buf.Bytes(), doc); err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0)
+		for name := range doc.Defs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, c.expected) {
+			t.Errorf("%v: got %v, expected %v", c.args, names, c.expected)
+		}
+	}
+
+	// OpenAPI document with title.
+	buf := &bytes.Buffer{}
+	if err := schema.Run([]string{"-title", "user service", "-t", "RunUser"}, buf); err != nil {
+		t.Fatal(err)
+	}
+	doc := &schema.Document{}
+	if err := json.Unmarshal(buf.Bytes(), doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title != "user service" || doc.Components.Schemas["RunUser"] == nil {
+		t.Errorf("unexpected document: %s", buf)
+	}
+
+	// Invalid selection and format.
+	for _, args := range [][]string{
+		{"-t", "Missing"},
+		{"-p", "example.com/missing"},
+		{"-f", "yaml"},
+		{"-unknown"},
+	} {
+		if err := schema.Run(args, ioutil.Discard); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
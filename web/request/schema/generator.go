@@ -0,0 +1,373 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	typeBytes  = reflect.TypeOf([]byte(nil))
+	typeNumber = reflect.TypeOf(json.Number(""))
+	typeRaw    = reflect.TypeOf(json.RawMessage(nil))
+	typeTime   = reflect.TypeOf(time.Time{})
+
+	// Formats of validate tags.
+	formats = map[string]string{
+		"email":    "email",
+		"hostname": "hostname",
+		"http_url": "uri",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+		"uri":      "uri",
+		"url":      "uri",
+		"uuid":     "uuid",
+		"uuid3":    "uuid",
+		"uuid4":    "uuid",
+		"uuid5":    "uuid",
+	}
+
+	// Patterns of validate tags.
+	patterns = map[string]string{
+		"alpha":    `^[a-zA-Z]+$`,
+		"alphanum": `^[a-zA-Z0-9]+$`,
+		"e164":     `^\+[1-9]?[0-9]{7,14}$`,
+		"number":   `^[0-9]+$`,
+		"numeric":  `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	}
+
+	rules   = make(map[string]Rule)
+	rulesMu sync.RWMutex
+)
+
+type (
+	// Rule
+	// apply custom validate tag on schema, t is type of field.
+	Rule func(s *Schema, t reflect.Type, param string)
+
+	generator struct {
+		defs   map[string]*Schema
+		names  map[reflect.Type]string
+		prefix string
+	}
+)
+
+// RegisterRule
+// mapping of custom validate tag, built-in mapping is replaced
+// if registered already.
+//
+//   schema.RegisterRule("cn_mobile", func(s *schema.Schema, _ reflect.Type, _ string) {
+//       s.Pattern = `^1[3-9]\d{9}$`
+//   })
+func RegisterRule(tag string, rule Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[tag] = rule
+}
+
+func newGenerator(prefix string) *generator {
+	return &generator{
+		defs:   make(map[string]*Schema),
+		names:  make(map[reflect.Type]string),
+		prefix: prefix,
+	}
+}
+
+// /////////////////////////////////////////////////////////////
+// Generator methods.
+// /////////////////////////////////////////////////////////////
+
+// Define
+// schema of type in defs.
+func (o *generator) define(name string, t reflect.Type) {
+	o.names[t] = name
+	o.defs[name] = o.schema(t, true)
+}
+
+// Name
+// return name of struct type in defs, package name is prefixed
+// if different types have same name.
+func (o *generator) name(t reflect.Type) string {
+	name := t.Name()
+	used := false
+	for _, n := range o.names {
+		used = used || n == name
+	}
+	if used {
+		if pkg := t.PkgPath(); pkg != "" {
+			name = strings.Replace(pkg[strings.LastIndex(pkg, "/")+1:], ".", "_", -1) + "." + name
+		}
+	}
+	return name
+}
+
+// Object
+// return schema of struct type.
+func (o *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	o.fields(s, t)
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+	return s
+}
+
+func (o *generator) fields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+
+		// Embedded struct without json name.
+		if f.Anonymous && name == "" {
+			if ft := indirect(f.Type); ft.Kind() == reflect.Struct {
+				o.fields(s, ft)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := o.schema(f.Type, false)
+		prop.Title = f.Tag.Get("label")
+		if def, ok := f.Tag.Lookup("default"); ok {
+			prop.Default = typedValue(f.Type, def)
+		}
+		if applyTag(prop, f.Type, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// Schema
+// return schema of type, named struct type is referenced unless
+// it is root.
+func (o *generator) schema(t reflect.Type, root bool) *Schema {
+	t = indirect(t)
+
+	switch t {
+	case typeTime:
+		return &Schema{Type: "string", Format: "date-time"}
+	case typeBytes:
+		return &Schema{Type: "string", ContentEncoding: "base64"}
+	case typeNumber:
+		return &Schema{Type: "number"}
+	case typeRaw:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: o.schema(t.Elem(), false)}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: o.schema(t.Elem(), false), MinItems: integer(t.Len()), MaxItems: integer(t.Len())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: o.schema(t.Elem(), false)}
+	case reflect.Struct:
+		if root || t.Name() == "" {
+			return o.object(t)
+		}
+		name, ok := o.names[t]
+		if !ok {
+			name = o.name(t)
+			o.define(name, t)
+		}
+		return &Schema{Ref: o.prefix + name}
+	}
+	return &Schema{}
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Apply tag
+// map rules of validate tag to keywords, return true if field
+// is required.
+//
+// Rules after dive are applied on items of array or values of
+// map.
+func applyTag(s *Schema, t reflect.Type, tag string) (required bool) {
+	for i, rule := range strings.Split(tag, ",") {
+		// Or-rules can not be expressed by keywords.
+		if rule == "" || strings.Contains(rule, "|") {
+			continue
+		}
+
+		name, param := rule, ""
+		if n := strings.Index(rule, "="); n > 0 {
+			name, param = rule[:n], rule[n+1:]
+		}
+
+		if name == "dive" {
+			rest := strings.Join(strings.Split(tag, ",")[i+1:], ",")
+			switch t = indirect(t); {
+			case s.Items != nil:
+				applyTag(s.Items, t.Elem(), rest)
+			case s.AdditionalProperties != nil:
+				applyTag(s.AdditionalProperties, t.Elem(), rest)
+			}
+			return
+		}
+		if name == "required" {
+			required = true
+			continue
+		}
+		applyRule(s, indirect(t), name, param)
+	}
+	return
+}
+
+func applyRule(s *Schema, t reflect.Type, name, param string) {
+	rulesMu.RLock()
+	rule, ok := rules[name]
+	rulesMu.RUnlock()
+	if ok {
+		rule(s, t, param)
+		return
+	}
+
+	if format, ok := formats[name]; ok {
+		s.Format = format
+		return
+	}
+	if pattern, ok := patterns[name]; ok {
+		s.Pattern = pattern
+		return
+	}
+
+	switch name {
+	case "oneof":
+		s.Enum = make([]interface{}, 0)
+		for _, v := range strings.Fields(param) {
+			s.Enum = append(s.Enum, typedValue(t, strings.Trim(v, "'")))
+		}
+	case "unique":
+		s.UniqueItems = true
+	case "eq":
+		s.Const = typedValue(t, param)
+	case "len":
+		applyBound(s, t, "min", param)
+		applyBound(s, t, "max", param)
+	case "min", "max", "gt", "gte", "lt", "lte":
+		applyBound(s, t, name, param)
+	}
+}
+
+// Apply bound
+// map min, max, gt, gte, lt and lte to keywords by kind of
+// field.
+func applyBound(s *Schema, t reflect.Type, name, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	var low, high **int
+	switch t.Kind() {
+	case reflect.String:
+		low, high = &s.MinLength, &s.MaxLength
+	case reflect.Slice, reflect.Array:
+		low, high = &s.MinItems, &s.MaxItems
+	case reflect.Map:
+		low, high = &s.MinProperties, &s.MaxProperties
+	}
+
+	// Length of string, array or map.
+	if low != nil {
+		switch name {
+		case "min", "gte":
+			*low = integer(int(n))
+		case "gt":
+			*low = integer(int(n) + 1)
+		case "max", "lte":
+			*high = integer(int(n))
+		case "lt":
+			*high = integer(int(n) - 1)
+		}
+		return
+	}
+
+	switch name {
+	case "min", "gte":
+		s.Minimum = float(n)
+	case "gt":
+		s.ExclusiveMinimum = float(n)
+	case "max", "lte":
+		s.Maximum = float(n)
+	case "lt":
+		s.ExclusiveMaximum = float(n)
+	}
+}
+
+// Field name
+// return json name of field, the second value is false if field
+// is ignored.
+func fieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	return strings.Split(tag, ",")[0], true
+}
+
+func float(n float64) *float64 { return &n }
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func integer(n int) *int { return &n }
+
+// Typed value
+// return value of string in kind of type, return string if it
+// can not be converted.
+func typedValue(t reflect.Type, s string) interface{} {
+	switch indirect(t).Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	}
+	return s
+}
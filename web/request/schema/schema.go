@@ -0,0 +1,245 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+// Package schema
+// generate JSON Schema (draft 2020-12) and OpenAPI 3 components
+// from request structs.
+//
+// Json tags are used as property names, label tags as titles,
+// validate tags are mapped to schema keywords.
+//
+//   type CreateUser struct {
+//       Name  string `json:"name" label:"Name" validate:"required,max=32"`
+//       Email string `json:"email" label:"Email" validate:"omitempty,email"`
+//   }
+//
+//   schema.Register(CreateUser{})
+//   doc := schema.OpenAPI("user service", "1.0.0")
+//
+// Run is entry of command which emits documents of registered
+// types.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+const (
+	FormatJSONSchema = "jsonschema"
+	FormatOpenAPI    = "openapi"
+
+	// Draft
+	// dialect of generated json schema.
+	Draft = "https://json-schema.org/draft/2020-12/schema"
+
+	// OpenAPIVersion
+	// version of generated openapi document, schema objects of
+	// it are json schema 2020-12.
+	OpenAPIVersion = "3.1.0"
+
+	refJSONSchema = "#/$defs/"
+	refOpenAPI    = "#/components/schemas/"
+)
+
+var (
+	registry = &types{list: make(map[string]reflect.Type)}
+)
+
+type (
+	// Schema
+	// json schema object.
+	Schema struct {
+		Schema string `json:"$schema,omitempty"`
+		Ref    string `json:"$ref,omitempty"`
+
+		Type        interface{} `json:"type,omitempty"`
+		Title       string      `json:"title,omitempty"`
+		Description string      `json:"description,omitempty"`
+		Format      string      `json:"format,omitempty"`
+		Pattern     string      `json:"pattern,omitempty"`
+
+		Const   interface{}   `json:"const,omitempty"`
+		Default interface{}   `json:"default,omitempty"`
+		Enum    []interface{} `json:"enum,omitempty"`
+
+		ContentEncoding string `json:"contentEncoding,omitempty"`
+
+		MinLength *int `json:"minLength,omitempty"`
+		MaxLength *int `json:"maxLength,omitempty"`
+
+		Minimum          *float64 `json:"minimum,omitempty"`
+		Maximum          *float64 `json:"maximum,omitempty"`
+		ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+		Items       *Schema `json:"items,omitempty"`
+		MinItems    *int    `json:"minItems,omitempty"`
+		MaxItems    *int    `json:"maxItems,omitempty"`
+		UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		MinProperties        *int               `json:"minProperties,omitempty"`
+		MaxProperties        *int               `json:"maxProperties,omitempty"`
+
+		Defs map[string]*Schema `json:"$defs,omitempty"`
+	}
+
+	// Document
+	// openapi document with schemas of components.
+	Document struct {
+		OpenAPI    string                 `json:"openapi"`
+		Info       Info                   `json:"info"`
+		Paths      map[string]interface{} `json:"paths"`
+		Components Components             `json:"components"`
+	}
+
+	// Info
+	// of openapi document.
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+
+	// Components
+	// of openapi document.
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	}
+
+	types struct {
+		mu   sync.RWMutex
+		list map[string]reflect.Type
+	}
+)
+
+// Register
+// request types to generate documents, type name is used as
+// name of schema if name not given.
+//
+//   schema.Register(CreateUser{})
+//   schema.Register(&UpdateUser{}, "UserUpdate")
+func Register(v interface{}, name ...string) {
+	t := indirect(reflect.TypeOf(v))
+
+	n := t.Name()
+	if len(name) > 0 && name[0] != "" {
+		n = name[0]
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.list[n] = t
+}
+
+// Registered
+// return sorted names of registered types.
+func Registered() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	list := make([]string, 0, len(registry.list))
+	for name := range registry.list {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// JSONSchema
+// return json schema of v, named struct types are defined in
+// $defs.
+func JSONSchema(v interface{}) *Schema {
+	g := newGenerator(refJSONSchema)
+	s := g.schema(indirect(reflect.TypeOf(v)), true)
+	s.Schema = Draft
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// JSONSchemas
+// return json schema document with registered types defined in
+// $defs.
+func JSONSchemas() *Schema { return jsonSchemas(registered()) }
+
+// OpenAPI
+// return openapi document with registered types defined in
+// components.
+func OpenAPI(title, version string) *Document { return openAPI(title, version, registered()) }
+
+// Write
+// indented document of registered types in format.
+//
+//   schema.Write(os.Stdout, schema.FormatOpenAPI, "user service", "1.0.0")
+func Write(w io.Writer, format, title, version string) error {
+	return write(w, format, title, version, registered())
+}
+
+// /////////////////////////////////////////////////////////////
+// Internal functions.
+// /////////////////////////////////////////////////////////////
+
+// Define
+// schemas of types in generator in order of names.
+func define(g *generator, list map[string]reflect.Type) {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.define(name, list[name])
+	}
+}
+
+func jsonSchemas(list map[string]reflect.Type) *Schema {
+	g := newGenerator(refJSONSchema)
+	define(g, list)
+	return &Schema{Schema: Draft, Defs: g.defs}
+}
+
+func openAPI(title, version string, list map[string]reflect.Type) *Document {
+	g := newGenerator(refOpenAPI)
+	define(g, list)
+	return &Document{
+		OpenAPI:    OpenAPIVersion,
+		Info:       Info{Title: title, Version: version},
+		Paths:      make(map[string]interface{}),
+		Components: Components{Schemas: g.defs},
+	}
+}
+
+func write(w io.Writer, format, title, version string, list map[string]reflect.Type) error {
+	var doc interface{}
+	switch format {
+	case FormatJSONSchema:
+		doc = jsonSchemas(list)
+	case FormatOpenAPI:
+		doc = openAPI(title, version, list)
+	default:
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(doc)
+}
+
+func registered() map[string]reflect.Type {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	list := make(map[string]reflect.Type, len(registry.list))
+	for name, t := range registry.list {
+		list[name] = t
+	}
+	return list
+}
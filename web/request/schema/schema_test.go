@@ -0,0 +1,69 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fuyibing/util/v8/web/request/schema"
+)
+
+type (
+	address struct {
+		City string `json:"city" label:"City" validate:"required,min=2,max=32"`
+	}
+
+	user struct {
+		Name    string         `json:"name" label:"Name" validate:"required,max=32"`
+		Email   string         `json:"email,omitempty" validate:"omitempty,email"`
+		Age     int            `json:"age" validate:"gte=18,lt=130" default:"20"`
+		Status  int            `json:"status" validate:"oneof=1 2 3"`
+		Tags    []string       `json:"tags" validate:"min=1,dive,alphanum,max=8"`
+		Address *address       `json:"address"`
+		Scores  map[string]int `json:"scores"`
+		Secret  string         `json:"-"`
+	}
+)
+
+func TestJSONSchema(t *testing.T) {
+	buf, err := json.Marshal(schema.JSONSchema(user{}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+		`"address":{"$ref":"#/$defs/address"},` +
+		`"age":{"type":"integer","default":20,"minimum":18,"exclusiveMaximum":130},` +
+		`"email":{"type":"string","format":"email"},` +
+		`"name":{"type":"string","title":"Name","maxLength":32},` +
+		`"scores":{"type":"object","additionalProperties":{"type":"integer"}},` +
+		`"status":{"type":"integer","enum":[1,2,3]},` +
+		`"tags":{"type":"array","items":{"type":"string","pattern":"^[a-zA-Z0-9]+$","maxLength":8},"minItems":1}` +
+		`},"required":["name"],"$defs":{"address":{"type":"object","properties":{` +
+		`"city":{"type":"string","title":"City","minLength":2,"maxLength":32}},"required":["city"]}}}`
+	if string(buf) != expected {
+		t.Fatalf("unexpected schema:\n%s", buf)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	schema.Register(&user{}, "User")
+
+	doc := schema.OpenAPI("user service", "1.0.0")
+	if doc.OpenAPI != schema.OpenAPIVersion || doc.Info.Title != "user service" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	s, ok := doc.Components.Schemas["User"]
+	if !ok {
+		t.Fatalf("schema of User not defined: %v", doc.Components.Schemas)
+	}
+	if ref := s.Properties["address"].Ref; ref != "#/components/schemas/address" {
+		t.Fatalf("unexpected reference: %s", ref)
+	}
+	if _, ok = doc.Components.Schemas["address"]; !ok {
+		t.Fatalf("schema of address not defined")
+	}
+}
@@ -0,0 +1,86 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request
+
+import (
+	"fmt"
+	"reflect"
+
+	i18nValidator "github.com/go-playground/validator/v10"
+)
+
+type (
+	// StructLevel
+	// used by struct-level rule to read struct and report
+	// failures against fields.
+	StructLevel struct {
+		sl i18nValidator.StructLevel
+	}
+)
+
+// RegisterStruct
+// struct-level rule for type of v, it is called after field
+// tags validated.
+//
+// Failure reported by Report is translated by message of key
+// registered by RegisterMessages.
+//
+//   v.RegisterMessages("after_start", map[string]string{
+//       request.LocaleEN: "{field} must be after {other}",
+//       request.LocaleZH: "{field}必须晚于{other}",
+//   })
+//   v.RegisterStruct(Event{}, func(sl *request.StructLevel) {
+//       e := sl.Struct().(Event)
+//       if !e.AllDay && !e.EndTime.After(e.StartTime) {
+//           sl.Report("EndTime", "after_start", "StartTime")
+//       }
+//   })
+func (o *Validator) RegisterStruct(v interface{}, fn func(sl *StructLevel)) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	o.valid.RegisterStructValidation(func(sl i18nValidator.StructLevel) {
+		fn(&StructLevel{sl: sl})
+	}, v)
+	return nil
+}
+
+// /////////////////////////////////////////////////////////////
+// StructLevel methods.
+// /////////////////////////////////////////////////////////////
+
+// Report
+// failure of field named by struct field name, key is used as
+// tag of field error and key of message. Param is formatted
+// into {param}, it is also resolved as {other} if it is name
+// of sibling field.
+//
+// It panics if struct has no field named by field, as name is
+// fixed in code of rule rather than input of request.
+func (o *StructLevel) Report(field, key string, param ...string) {
+	current := o.sl.Current()
+	f, ok := current.Type().FieldByName(field)
+	if !ok {
+		panic(fmt.Sprintf("field '%s' not found in %s", field, current.Type()))
+	}
+
+	label := f.Tag.Get("label")
+	if label == "" {
+		label = f.Name
+	}
+
+	p := ""
+	if len(param) > 0 {
+		p = param[0]
+	}
+	o.sl.ReportError(current.FieldByIndex(f.Index).Interface(), label, f.Name, key, p)
+}
+
+// Struct
+// return struct value being validated.
+func (o *StructLevel) Struct() interface{} { return o.sl.Current().Interface() }
+
+// Value
+// return reflect value of struct being validated.
+func (o *StructLevel) Value() reflect.Value { return o.sl.Current() }
@@ -0,0 +1,83 @@
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-01
+
+package request_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fuyibing/util/v8/web/request"
+)
+
+type (
+	structEvent struct {
+		StartTime time.Time `json:"start_time" label:"Start time"`
+		EndTime   time.Time `json:"end_time" label:"End time"`
+		AllDay    bool      `json:"all_day"`
+	}
+)
+
+func TestValidator_RegisterStruct(t *testing.T) {
+	v, err := request.NewValidator(
+		request.WithMessages("after_start", map[string]string{
+			request.LocaleEN: "{field} must be after {other}",
+			request.LocaleZH: "{field}必须晚于{other}",
+		}),
+		request.WithStruct(structEvent{}, func(sl *request.StructLevel) {
+			e := sl.Struct().(structEvent)
+			if !e.AllDay && !e.EndTime.After(e.StartTime) {
+				sl.Report("EndTime", "after_start", "StartTime")
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		name     string
+		event    structEvent
+		locale   string
+		expected string
+	}{
+		{"after", structEvent{StartTime: start, EndTime: start.Add(time.Hour)}, request.LocaleEN, ""},
+		{"all day", structEvent{StartTime: start, EndTime: start, AllDay: true}, request.LocaleEN, ""},
+		{"before", structEvent{StartTime: start, EndTime: start.Add(-time.Hour)}, request.LocaleEN, "End time must be after Start time"},
+		{"equal", structEvent{StartTime: start, EndTime: start}, request.LocaleZH, "End time必须晚于Start time"},
+	} {
+		err = v.StructLocale(&c.event, c.locale)
+		if c.expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(request.FieldErrors)
+		if !ok || len(errs) != 1 {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+			continue
+		}
+		if e := errs[0]; e.Tag != "after_start" || e.Field != "end_time" || e.Param != "StartTime" || e.Message != c.expected {
+			t.Errorf("%s: unexpected failure: %+v", c.name, e)
+		}
+	}
+}
+
+func TestStructLevel_Report_UnknownField(t *testing.T) {
+	v, err := request.NewValidator(request.WithStruct(structEvent{}, func(sl *request.StructLevel) {
+		sl.Report("Missing", "after_start")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic of unknown field")
+		}
+	}()
+	_ = v.Struct(&structEvent{})
+}
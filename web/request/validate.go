@@ -4,18 +4,21 @@
 package request
 
 import (
-	"encoding/json"
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/go-playground/locales"
 	i18nEN "github.com/go-playground/locales/en"
 	i18nZH "github.com/go-playground/locales/zh"
+	i18nZHHant "github.com/go-playground/locales/zh_Hant"
 	i18nTranslator "github.com/go-playground/universal-translator"
 	i18nValidator "github.com/go-playground/validator/v10"
 	i18nENTranslations "github.com/go-playground/validator/v10/translations/en"
 	i18nZHTranslations "github.com/go-playground/validator/v10/translations/zh"
+	i18nZHHantTranslations "github.com/go-playground/validator/v10/translations/zh_tw"
 
 	"reflect"
+	"sort"
 )
 
 var (
@@ -24,66 +27,173 @@ var (
 )
 
 type (
+	// Validator
+	// validate structs with translated failures, use NewValidator
+	// to build independent and frozen one.
 	Validator struct {
-		trans i18nTranslator.Translator
-		valid *i18nValidator.Validate
+		decoders  map[string]Decoder
+		frozen    bool
+		json      JSONOptions
+		locale    string
+		messages  map[string]map[string]string
+		modifiers map[string]Modifier
+		trans     map[string]i18nTranslator.Translator
+		valid     *i18nValidator.Validate
 	}
 )
 
 // Register
-// custom tag for validation.
+// custom tag for validation, message is registered in all
+// locales.
+//
+// Message supports placeholders of RegisterWithMessages.
 func (o *Validator) Register(tag, message string, check func(f i18nValidator.FieldLevel) bool) error {
-	if err := o.valid.RegisterValidation(tag, check); err != nil {
-		return err
-	}
+	return o.RegisterWithMessages(tag, map[string]string{"": message}, check)
+}
 
-	return o.valid.RegisterTranslation(tag, o.trans,
-		func(ut i18nTranslator.Translator) error {
-			return ut.Add(tag, message, true)
-		},
-		func(ut i18nTranslator.Translator, fe i18nValidator.FieldError) string {
-			us, ue := ut.T(fe.Tag(), fe.Field())
-			if ue != nil {
-				return fe.(error).Error()
-			}
-			return us
-		},
-	)
+// Translate
+// message of tag in specified locale, replace message
+// registered by Register.
+//
+//   v.Register("cn_mobile", "{0} must be a valid mobile number", check)
+//   v.Translate("cn_mobile", request.LocaleZH, "{0}必须是有效的手机号码")
+func (o *Validator) Translate(tag, locale, message string) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	if _, ok := o.trans[locale]; !ok {
+		return fmt.Errorf("locale '%s' not registered", locale)
+	}
+	if _, ok := o.messages[tag]; !ok {
+		o.messages[tag] = make(map[string]string)
+	}
+	o.messages[tag][locale] = message
+	return nil
 }
 
 // Body
 // unmarshal into struct and return validate result.
+//
+// Return DecodeError of ErrInvalidJson if body can not be
+// unmarshalled, or FieldErrors if validation failed.
 func (o *Validator) Body(v interface{}, body []byte) error {
-	if err := json.Unmarshal(body, v); err != nil {
-		return ErrInvalidJson
+	return o.BodyLocale(v, body, o.locale)
+}
+
+// BodyLocale
+// unmarshal into struct and return validate result translated
+// in specified locale.
+func (o *Validator) BodyLocale(v interface{}, body []byte, locale string) error {
+	return o.Decode(v, ContentTypeJson, bytes.NewReader(body), locale)
+}
+
+// Locale
+// return default locale.
+func (o *Validator) Locale() string { return o.locale }
+
+// Locales
+// return sorted locales registered.
+func (o *Validator) Locales() []string {
+	list := make([]string, 0, len(o.trans))
+	for locale := range o.trans {
+		list = append(list, locale)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Negotiate
+// return best locale for Accept-Language header, return
+// default locale if no locale matched.
+//
+//   v.Negotiate("zh-TW,zh;q=0.9,en;q=0.8") // return "zh_Hant"
+//   v.Negotiate("en-US,en;q=0.9")          // return "en"
+func (o *Validator) Negotiate(header string) string {
+	for _, lang := range ParseAcceptLanguage(header) {
+		if locale, ok := matchLocale(lang.Tag, o.trans); ok {
+			return locale
+		}
+		if lang.Tag == "*" {
+			break
+		}
 	}
-	return o.Struct(v)
+	return o.locale
 }
 
 // Struct
 // return validate result.
+//
+// Return FieldErrors with failures of all invalid fields if
+// validation failed.
 func (o *Validator) Struct(v interface{}) error {
-	if e0 := o.valid.Struct(v); e0 != nil {
-		for _, e1 := range e0.(i18nValidator.ValidationErrors) {
-			return errors.New(e1.Translate(o.trans))
+	return o.StructLocale(v, o.locale)
+}
+
+// StructContext
+// return validate result translated in locale carried by ctx.
+//
+//   ctx = request.NewLocaleContext(ctx, request.Validate.Negotiate(r.Header.Get("Accept-Language")))
+//   err := request.Validate.StructContext(ctx, v)
+func (o *Validator) StructContext(ctx context.Context, v interface{}) error {
+	return o.StructLocale(v, LocaleFromContext(ctx))
+}
+
+// StructLocale
+// return validate result translated in specified locale, use
+// default locale if not registered.
+//
+// Default and mod tags are applied before validation.
+func (o *Validator) StructLocale(v interface{}, locale string) error {
+	return o.validate(v, locale, func() error { return o.valid.Struct(v) })
+}
+
+// With
+// translator register, locale of fallback is used as default
+// locale.
+func (o *Validator) With(register func(*i18nValidator.Validate, i18nTranslator.Translator) error, fallback locales.Translator, supports ...locales.Translator) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	if trans := i18nTranslator.New(fallback, supports...).GetFallback(); trans != nil {
+		if _, ok := o.trans[trans.Locale()]; !ok {
+			if err := register(o.valid, trans); err != nil {
+				return err
+			}
+			o.trans[trans.Locale()] = trans
 		}
+		o.locale = trans.Locale()
 	}
 	return nil
 }
 
-// With
-// translator register.
-func (o *Validator) With(register func(*i18nValidator.Validate, i18nTranslator.Translator) error, fallback locales.Translator, supports ...locales.Translator) {
-	if o.trans = i18nTranslator.New(fallback, supports...).GetFallback(); o.trans != nil {
-		_ = register(o.valid, o.trans)
-	}
+func (o *Validator) WithEN() error {
+	return o.With(i18nENTranslations.RegisterDefaultTranslations, i18nEN.New())
 }
 
-func (o *Validator) WithEN() { o.With(i18nENTranslations.RegisterDefaultTranslations, i18nEN.New()) }
-func (o *Validator) WithZH() { o.With(i18nZHTranslations.RegisterDefaultTranslations, i18nZH.New()) }
+func (o *Validator) WithZH() error {
+	return o.With(i18nZHTranslations.RegisterDefaultTranslations, i18nZH.New())
+}
+
+func (o *Validator) WithZHHant() error {
+	return o.With(i18nZHHantTranslations.RegisterDefaultTranslations, i18nZHHant.New())
+}
 
 func (o *Validator) init() *Validator {
+	o.messages = make(map[string]map[string]string)
+	o.messages[TagType] = make(map[string]string)
+	for locale, message := range bindMessages {
+		o.messages[TagType][locale] = message
+	}
+	for key, messages := range pageMessages {
+		o.messages[key] = make(map[string]string)
+		for locale, message := range messages {
+			o.messages[key][locale] = message
+		}
+	}
+	o.trans = make(map[string]i18nTranslator.Translator)
 	o.valid = i18nValidator.New()
+	o.initDecoders()
+	o.initModifiers()
 
 	o.valid.RegisterTagNameFunc(func(field reflect.StructField) string {
 		if v := field.Tag.Get("label"); v != "" {
@@ -92,6 +202,29 @@ func (o *Validator) init() *Validator {
 		return field.Name
 	})
 
-	o.WithZH()
+	// Register all supported locales, the last one is default.
+	_ = o.WithEN()
+	_ = o.WithZHHant()
+	_ = o.WithZH()
 	return o
 }
+
+// Translate
+// return function translate field error in locale, message
+// registered by Register is preferred.
+func (o *Validator) translate(v interface{}, locale string) func(fe i18nValidator.FieldError) string {
+	trans := o.translator(locale)
+	return func(fe i18nValidator.FieldError) string {
+		if message, ok := o.message(fe.Tag(), locale); ok {
+			return formatMessage(message, fe.Field(), fe.Param(), fe.Value(), otherLabel(reflect.TypeOf(v), fe.StructNamespace(), fe.Param()))
+		}
+		return fe.Translate(trans)
+	}
+}
+
+func (o *Validator) translator(locale string) i18nTranslator.Translator {
+	if trans, ok := o.trans[locale]; ok {
+		return trans
+	}
+	return o.trans[o.locale]
+}
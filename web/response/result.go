@@ -24,8 +24,9 @@ const (
 	TypeList   Type = "LIST"
 	TypePaging Type = "PAGING"
 
-	ResultFieldForBody  = "body"
-	ResultNameForPaging = "paging"
+	ResultFieldForBody   = "body"
+	ResultFieldForFields = "fields"
+	ResultNameForPaging  = "paging"
 )
 
 func NewResult(dt Type) *Result {